@@ -1,126 +1,315 @@
 package main
 
-import "strings"
+import (
+	"fmt"
+	"time"
 
+	"github.com/shopspring/decimal"
+)
+
+// BalanceSheet holds every fiscal year of balance sheets that was loaded
 type BalanceSheet struct {
-	Y2018 *YearBalanceSheet
-	Y2019 *YearBalanceSheet
-	Y2020 *YearBalanceSheet
-	Y2021 *YearBalanceSheet
-	// Y2022
+	Series[*YearBalanceSheet]
 }
 
 type YearBalanceSheet struct {
-	totalCurrentAssets      int64
-	totalCurrentLiabilities int64
-	totalLiabilities        int64
-	totalShareholdersEquity int64
-	shortTermDebt           int64
-	longTermDebt            int64
-	totalAssets             int64
+	Year int
+	End  time.Time
+	// Currency the amounts are reported in, each line item is an exact amount of it
+	Currency                     string
+	cash                         decimal.Decimal
+	shortTermInvestments         decimal.Decimal
+	netReceivables               decimal.Decimal
+	inventory                    decimal.Decimal
+	otherCurrentAssets           decimal.Decimal
+	totalCurrentAssets           decimal.Decimal
+	longTermInvestments          decimal.Decimal
+	propertyPlantEquipment       decimal.Decimal
+	goodWill                     decimal.Decimal
+	intangibleAssets             decimal.Decimal
+	otherAssets                  decimal.Decimal
+	deferredLongTermAssetCharges decimal.Decimal
+	totalAssets                  decimal.Decimal
+	accountsPayable              decimal.Decimal
+	shortTermDebt                decimal.Decimal
+	otherCurrentLiabilities      decimal.Decimal
+	totalCurrentLiabilities      decimal.Decimal
+	longTermDebt                 decimal.Decimal
+	deferredLongTermLiabilities  decimal.Decimal
+	minorityInterest             decimal.Decimal
+	otherLiabilities             decimal.Decimal
+	totalLiabilities             decimal.Decimal
+	preferredStock               decimal.Decimal
+	commonStock                  decimal.Decimal
+	capitalSurplus               decimal.Decimal
+	retainedEarnings             decimal.Decimal
+	treasuryStock                decimal.Decimal
+	otherStockholderEquity       decimal.Decimal
+	totalShareholdersEquity      decimal.Decimal
+	netTangibleAssets            decimal.Decimal
+}
+
+// balanceSheetItems maps a normalised Yahoo line item name (upper case, no spaces) onto its YearBalanceSheet field
+var balanceSheetItems = map[string]func(b *YearBalanceSheet, v int64){
+	"CASH":                         func(b *YearBalanceSheet, v int64) { b.cash = decimal.New(v, 0) },
+	"SHORTTERMINVESTMENTS":         func(b *YearBalanceSheet, v int64) { b.shortTermInvestments = decimal.New(v, 0) },
+	"NETRECEIVABLES":               func(b *YearBalanceSheet, v int64) { b.netReceivables = decimal.New(v, 0) },
+	"INVENTORY":                    func(b *YearBalanceSheet, v int64) { b.inventory = decimal.New(v, 0) },
+	"OTHERCURRENTASSETS":           func(b *YearBalanceSheet, v int64) { b.otherCurrentAssets = decimal.New(v, 0) },
+	"TOTALCURRENTASSETS":           func(b *YearBalanceSheet, v int64) { b.totalCurrentAssets = decimal.New(v, 0) },
+	"LONGTERMINVESTMENTS":          func(b *YearBalanceSheet, v int64) { b.longTermInvestments = decimal.New(v, 0) },
+	"PROPERTYPLANTEQUIPMENT":       func(b *YearBalanceSheet, v int64) { b.propertyPlantEquipment = decimal.New(v, 0) },
+	"GOODWILL":                     func(b *YearBalanceSheet, v int64) { b.goodWill = decimal.New(v, 0) },
+	"INTANGIBLEASSETS":             func(b *YearBalanceSheet, v int64) { b.intangibleAssets = decimal.New(v, 0) },
+	"OTHERASSETS":                  func(b *YearBalanceSheet, v int64) { b.otherAssets = decimal.New(v, 0) },
+	"DEFERREDLONGTERMASSETCHARGES": func(b *YearBalanceSheet, v int64) { b.deferredLongTermAssetCharges = decimal.New(v, 0) },
+	"TOTALASSETS":                  func(b *YearBalanceSheet, v int64) { b.totalAssets = decimal.New(v, 0) },
+	"ACCOUNTSPAYABLE":              func(b *YearBalanceSheet, v int64) { b.accountsPayable = decimal.New(v, 0) },
+	"SHORTLONGTERMDEBT":            func(b *YearBalanceSheet, v int64) { b.shortTermDebt = decimal.New(v, 0) },
+	"OTHERCURRENTLIAB":             func(b *YearBalanceSheet, v int64) { b.otherCurrentLiabilities = decimal.New(v, 0) },
+	"TOTALCURRENTLIABILITIES":      func(b *YearBalanceSheet, v int64) { b.totalCurrentLiabilities = decimal.New(v, 0) },
+	"LONGTERMDEBT":                 func(b *YearBalanceSheet, v int64) { b.longTermDebt = decimal.New(v, 0) },
+	"DEFERREDLONGTERMLIAB":         func(b *YearBalanceSheet, v int64) { b.deferredLongTermLiabilities = decimal.New(v, 0) },
+	"MINORITYINTEREST":             func(b *YearBalanceSheet, v int64) { b.minorityInterest = decimal.New(v, 0) },
+	"OTHERLIAB":                    func(b *YearBalanceSheet, v int64) { b.otherLiabilities = decimal.New(v, 0) },
+	"TOTALLIAB":                    func(b *YearBalanceSheet, v int64) { b.totalLiabilities = decimal.New(v, 0) },
+	"PREFERREDSTOCK":               func(b *YearBalanceSheet, v int64) { b.preferredStock = decimal.New(v, 0) },
+	"COMMONSTOCK":                  func(b *YearBalanceSheet, v int64) { b.commonStock = decimal.New(v, 0) },
+	"CAPITALSURPLUS":               func(b *YearBalanceSheet, v int64) { b.capitalSurplus = decimal.New(v, 0) },
+	"RETAINEDEARNINGS":             func(b *YearBalanceSheet, v int64) { b.retainedEarnings = decimal.New(v, 0) },
+	"TREASURYSTOCK":                func(b *YearBalanceSheet, v int64) { b.treasuryStock = decimal.New(v, 0) },
+	"OTHERSTOCKHOLDEREQUITY":       func(b *YearBalanceSheet, v int64) { b.otherStockholderEquity = decimal.New(v, 0) },
+	"TOTALSTOCKHOLDEREQUITY":       func(b *YearBalanceSheet, v int64) { b.totalShareholdersEquity = decimal.New(v, 0) },
+	"NETTANGIBLEASSETS":            func(b *YearBalanceSheet, v int64) { b.netTangibleAssets = decimal.New(v, 0) },
 }
 
-// NewBalanceSheet creates a BalanceSheet from Yahoo API data
+// NewBalanceSheet creates a BalanceSheet from Yahoo API data, see SetCurrency
 func NewBalanceSheet(ybs *YahooBalanceSheetV1) *BalanceSheet {
-	bs := BalanceSheet{
-		Y2018: &YearBalanceSheet{},
-		Y2019: &YearBalanceSheet{},
-		Y2020: &YearBalanceSheet{},
-		Y2021: &YearBalanceSheet{},
-	}
+	bs := BalanceSheet{}
 
 	for _, item := range ybs.Root {
-		name := strings.ToUpper(strings.ReplaceAll(item.Name, " ", ""))
-
-		switch name {
-		case "TOTALCURRENTASSETS":
-			bs.Y2018.totalCurrentAssets = item.Y2018
-			bs.Y2019.totalCurrentAssets = item.Y2019
-			bs.Y2020.totalCurrentAssets = item.Y2020
-			bs.Y2021.totalCurrentAssets = item.Y2021
-		case "TOTALCURRENTLIABILITIES":
-			bs.Y2018.totalCurrentLiabilities = item.Y2018
-			bs.Y2019.totalCurrentLiabilities = item.Y2019
-			bs.Y2020.totalCurrentLiabilities = item.Y2020
-			bs.Y2021.totalCurrentLiabilities = item.Y2021
-		case "TOTALLIAB":
-			bs.Y2018.totalLiabilities = item.Y2018
-			bs.Y2019.totalLiabilities = item.Y2019
-			bs.Y2020.totalLiabilities = item.Y2020
-			bs.Y2021.totalLiabilities = item.Y2021
-		case "TOTALSTOCKHOLDEREQUITY":
-			bs.Y2018.totalShareholdersEquity = item.Y2018
-			bs.Y2019.totalShareholdersEquity = item.Y2019
-			bs.Y2020.totalShareholdersEquity = item.Y2020
-			bs.Y2021.totalShareholdersEquity = item.Y2021
-		case "SHORTLONGTERMDEBT":
-			bs.Y2018.shortTermDebt = item.Y2018
-			bs.Y2019.shortTermDebt = item.Y2019
-			bs.Y2020.shortTermDebt = item.Y2020
-			bs.Y2021.shortTermDebt = item.Y2021
-		case "LONGTERMDEBT":
-			bs.Y2018.longTermDebt = item.Y2018
-			bs.Y2019.longTermDebt = item.Y2019
-			bs.Y2020.longTermDebt = item.Y2020
-			bs.Y2021.longTermDebt = item.Y2021
-		case "TOTALASSETS":
-			bs.Y2018.totalAssets = item.Y2018
-			bs.Y2019.totalAssets = item.Y2019
-			bs.Y2020.totalAssets = item.Y2020
-			bs.Y2021.totalAssets = item.Y2021
+		name := normaliseLineItem(item.Name)
+
+		set, ok := balanceSheetItems[name]
+
+		if !ok {
+			continue
 		}
 
+		for end, v := range item.Values {
+			y, ok := bs.Get(end)
+
+			if !ok {
+				y = &YearBalanceSheet{Year: end.Year(), End: end}
+				bs.Set(end, y)
+			}
+
+			set(y, v)
+		}
 	}
 
 	return &bs
 }
 
+// SetCurrency sets the currency of every year, Yahoo reports it with the stock rather than the statement
+func (B *BalanceSheet) SetCurrency(currency string) {
+	for _, y := range B.Values() {
+		y.Currency = currency
+	}
+}
+
 // TotalAssets
-func (b *YearBalanceSheet) TotalAssets() int64 {
-	return b.totalAssets
+func (b *YearBalanceSheet) TotalAssets() Money {
+	return b.money(b.totalAssets)
 }
 
 // TotalCurrentAssets
-func (b *YearBalanceSheet) TotalCurrentAssets() int64 {
-	return b.totalCurrentAssets
+func (b *YearBalanceSheet) TotalCurrentAssets() Money {
+	return b.money(b.totalCurrentAssets)
 }
 
 // TotalCurrentLiabilities
-func (b *YearBalanceSheet) TotalCurrentLiabilities() int64 {
-	return b.totalCurrentLiabilities
+func (b *YearBalanceSheet) TotalCurrentLiabilities() Money {
+	return b.money(b.totalCurrentLiabilities)
 }
 
 // TotalLiabilities
-func (b *YearBalanceSheet) TotalLiabilities() int64 {
-	return b.totalLiabilities
+func (b *YearBalanceSheet) TotalLiabilities() Money {
+	return b.money(b.totalLiabilities)
 }
 
 // TotalShareholdersEquity (Straight from Yahoo) aka BookValue
-func (b *YearBalanceSheet) TotalShareholdersEquity() int64 {
-	return b.totalShareholdersEquity
+func (b *YearBalanceSheet) TotalShareholdersEquity() Money {
+	return b.money(b.totalShareholdersEquity)
 }
 
 // ShareholdersEquity (TotalAssets - TotalLiabilities) aka BookValue
-func (b *YearBalanceSheet) ShareholdersEquity() int64 {
-	return b.TotalAssets() - b.TotalLiabilities()
+func (b *YearBalanceSheet) ShareholdersEquity() Money {
+	return b.money(b.totalAssets.Sub(b.totalLiabilities))
 }
 
 // ShortTermDebt
-func (b *YearBalanceSheet) ShortTermDebt() int64 {
-	return b.shortTermDebt
+func (b *YearBalanceSheet) ShortTermDebt() Money {
+	return b.money(b.shortTermDebt)
 }
 
 // LongTermDebt
-func (b *YearBalanceSheet) LongTermDebt() int64 {
-	return b.longTermDebt
+func (b *YearBalanceSheet) LongTermDebt() Money {
+	return b.money(b.longTermDebt)
+}
+
+// NetReceivables (money owed by customers)
+func (b *YearBalanceSheet) NetReceivables() Money {
+	return b.money(b.netReceivables)
+}
+
+// PropertyPlantEquipment (net of depreciation)
+func (b *YearBalanceSheet) PropertyPlantEquipment() Money {
+	return b.money(b.propertyPlantEquipment)
 }
 
 // Current Ratio (TotalCurrentAssets / TotalCurrentLiabilities)
-func (b *YearBalanceSheet) CurrentRatio() float32 {
-	return float32(b.TotalCurrentAssets()) / float32(b.TotalCurrentLiabilities())
+func (b *YearBalanceSheet) CurrentRatio() (decimal.Decimal, error) {
+	return Ratio(b.TotalCurrentAssets(), b.TotalCurrentLiabilities())
 }
 
 // DebtToShareholderEquityRatio (TotalLiabilities / ShareHoldersEquity)
-func (b *YearBalanceSheet) DebtToShareholderEquityRatio() float32 {
-	return float32(b.TotalLiabilities()) / float32(b.TotalShareholdersEquity())
+func (b *YearBalanceSheet) DebtToShareholderEquityRatio() (decimal.Decimal, error) {
+	return Ratio(b.TotalLiabilities(), b.TotalShareholdersEquity())
+}
+
+// ShortToLongTermDebtRatio (ShortTermDebt / LongTermDebt)
+func (b *YearBalanceSheet) ShortToLongTermDebtRatio() (decimal.Decimal, error) {
+	return Ratio(b.ShortTermDebt(), b.LongTermDebt())
+}
+
+// Cash (and cash equivalents)
+func (b *YearBalanceSheet) Cash() Money {
+	return b.money(b.cash)
+}
+
+// ShortTermInvestments (marketable securities due within a year)
+func (b *YearBalanceSheet) ShortTermInvestments() Money {
+	return b.money(b.shortTermInvestments)
+}
+
+// Inventory
+func (b *YearBalanceSheet) Inventory() Money {
+	return b.money(b.inventory)
+}
+
+// OtherCurrentAssets (prepaid expenses and other)
+func (b *YearBalanceSheet) OtherCurrentAssets() Money {
+	return b.money(b.otherCurrentAssets)
+}
+
+// LongTermInvestments
+func (b *YearBalanceSheet) LongTermInvestments() Money {
+	return b.money(b.longTermInvestments)
+}
+
+// GoodWill (price paid for acquisitions above their net assets)
+func (b *YearBalanceSheet) GoodWill() Money {
+	return b.money(b.goodWill)
+}
+
+// IntangibleAssets (patents, trademarks, copyrights, franchises)
+func (b *YearBalanceSheet) IntangibleAssets() Money {
+	return b.money(b.intangibleAssets)
+}
+
+// OtherAssets (other long-term assets)
+func (b *YearBalanceSheet) OtherAssets() Money {
+	return b.money(b.otherAssets)
+}
+
+// DeferredLongTermAssetCharges
+func (b *YearBalanceSheet) DeferredLongTermAssetCharges() Money {
+	return b.money(b.deferredLongTermAssetCharges)
+}
+
+// AccountsPayable
+func (b *YearBalanceSheet) AccountsPayable() Money {
+	return b.money(b.accountsPayable)
+}
+
+// OtherCurrentLiabilities (accrued expenses and other)
+func (b *YearBalanceSheet) OtherCurrentLiabilities() Money {
+	return b.money(b.otherCurrentLiabilities)
+}
+
+// DeferredLongTermLiabilities (deferred income tax)
+func (b *YearBalanceSheet) DeferredLongTermLiabilities() Money {
+	return b.money(b.deferredLongTermLiabilities)
+}
+
+// MinorityInterest (the part of subsidiaries owned by others)
+func (b *YearBalanceSheet) MinorityInterest() Money {
+	return b.money(b.minorityInterest)
+}
+
+// OtherLiabilities
+func (b *YearBalanceSheet) OtherLiabilities() Money {
+	return b.money(b.otherLiabilities)
+}
+
+// PreferredStock
+func (b *YearBalanceSheet) PreferredStock() Money {
+	return b.money(b.preferredStock)
+}
+
+// CommonStock (including paid in capital)
+func (b *YearBalanceSheet) CommonStock() Money {
+	return b.money(b.commonStock)
+}
+
+// CapitalSurplus (paid in capital above par)
+func (b *YearBalanceSheet) CapitalSurplus() Money {
+	return b.money(b.capitalSurplus)
+}
+
+// RetainedEarnings (net earnings kept in the business)
+func (b *YearBalanceSheet) RetainedEarnings() Money {
+	return b.money(b.retainedEarnings)
+}
+
+// TreasuryStock
+func (b *YearBalanceSheet) TreasuryStock() Money {
+	return b.money(b.treasuryStock)
+}
+
+// OtherStockholderEquity
+func (b *YearBalanceSheet) OtherStockholderEquity() Money {
+	return b.money(b.otherStockholderEquity)
+}
+
+// ReportedNetTangibleAssets as reported by Yahoo, see NetTangibleAssets
+func (b *YearBalanceSheet) ReportedNetTangibleAssets() Money {
+	return b.money(b.netTangibleAssets)
+}
+
+// WorkingCapital (TotalCurrentAssets - TotalCurrentLiabilities)
+func (b *YearBalanceSheet) WorkingCapital() Money {
+	return b.money(b.totalCurrentAssets.Sub(b.totalCurrentLiabilities))
+}
+
+// NetTangibleAssets (TotalShareholdersEquity - PreferredStock - GoodWill - IntangibleAssets), what common
+// shareholders would be left with if the assets that can't be sold on their own were written off
+func (b *YearBalanceSheet) NetTangibleAssets() Money {
+	return b.money(b.totalShareholdersEquity.Sub(b.preferredStock).Sub(b.goodWill).Sub(b.intangibleAssets))
+}
+
+// LongTermDebtYearsToRepay (LongTermDebt / netEarnings), the years of earnings it would take to pay off long-term
+// debt. ErrNotMeaningful when there are no earnings to repay it from
+func (b *YearBalanceSheet) LongTermDebtYearsToRepay(netEarnings Money) (decimal.Decimal, error) {
+	if !netEarnings.Amount.IsPositive() {
+		return decimal.Decimal{}, fmt.Errorf("no earnings to repay debt from: %w", ErrNotMeaningful)
+	}
+
+	return Ratio(b.LongTermDebt(), netEarnings)
+}
+
+// money returns an amount of the balance sheet in its currency
+func (b *YearBalanceSheet) money(v decimal.Decimal) Money {
+	return Money{Amount: v, Currency: b.Currency}
 }
@@ -1,98 +1,206 @@
 package main
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestBalanceTotalCurrentAssets(t *testing.T) {
 	// Arrange
 	m := YahooMockClient{}
-	ybs, _ := m.GetBalanceSheet("")
+	ybs, _ := m.GetBalanceSheet("AAPL")
 	bs := NewBalanceSheet(ybs)
 
 	// Act / Assert
-	assert.NotZero(t, bs.Y2018.TotalCurrentAssets())
-	assert.NotZero(t, bs.Y2019.TotalCurrentAssets())
-	assert.NotZero(t, bs.Y2020.TotalCurrentAssets())
-	assert.NotZero(t, bs.Y2021.TotalCurrentAssets())
+	assert.NotZero(t, bs.Year(2018).TotalCurrentAssets())
+	assert.NotZero(t, bs.Year(2019).TotalCurrentAssets())
+	assert.NotZero(t, bs.Year(2020).TotalCurrentAssets())
+	assert.NotZero(t, bs.Year(2021).TotalCurrentAssets())
 }
 
 func TestBalanceTotalCurrentLiabilities(t *testing.T) {
 	// Arrange
 	m := YahooMockClient{}
-	ybs, _ := m.GetBalanceSheet("")
+	ybs, _ := m.GetBalanceSheet("AAPL")
 	bs := NewBalanceSheet(ybs)
 
 	// Act / Assert
-	assert.NotZero(t, bs.Y2018.TotalCurrentLiabilities())
-	assert.NotZero(t, bs.Y2019.TotalCurrentLiabilities())
-	assert.NotZero(t, bs.Y2020.TotalCurrentLiabilities())
-	assert.NotZero(t, bs.Y2021.TotalCurrentLiabilities())
+	assert.NotZero(t, bs.Year(2018).TotalCurrentLiabilities())
+	assert.NotZero(t, bs.Year(2019).TotalCurrentLiabilities())
+	assert.NotZero(t, bs.Year(2020).TotalCurrentLiabilities())
+	assert.NotZero(t, bs.Year(2021).TotalCurrentLiabilities())
 }
 
 func TestBalanceCurrentRatio(t *testing.T) {
 	// Arrange
 	m := YahooMockClient{}
-	ybs, _ := m.GetBalanceSheet("")
+	ybs, _ := m.GetBalanceSheet("AAPL")
 	bs := NewBalanceSheet(ybs)
 
 	// Act / Assert
-	assert.NotZero(t, bs.Y2018.CurrentRatio())
-	assert.NotZero(t, bs.Y2019.CurrentRatio())
-	assert.NotZero(t, bs.Y2020.CurrentRatio())
-	assert.NotZero(t, bs.Y2021.CurrentRatio())
+	for _, y := range bs.Values() {
+		r, err := y.CurrentRatio()
+
+		assert.NoError(t, err)
+		assert.False(t, r.IsZero())
+	}
 }
 
 func TestBalanceDebtToShareholderEquityRatio(t *testing.T) {
 	// Arrange
 	m := YahooMockClient{}
-	ybs, _ := m.GetBalanceSheet("")
+	ybs, _ := m.GetBalanceSheet("AAPL")
 	bs := NewBalanceSheet(ybs)
 
 	// Act / Assert
-	assert.NotZero(t, bs.Y2018.DebtToShareholderEquityRatio())
-	assert.NotZero(t, bs.Y2019.DebtToShareholderEquityRatio())
-	assert.NotZero(t, bs.Y2020.DebtToShareholderEquityRatio())
-	assert.NotZero(t, bs.Y2021.DebtToShareholderEquityRatio())
+	for _, y := range bs.Values() {
+		r, err := y.DebtToShareholderEquityRatio()
+
+		assert.NoError(t, err)
+		assert.False(t, r.IsZero())
+	}
+}
+
+func TestBalanceCurrentRatioNotMeaningful(t *testing.T) {
+	// Arrange
+	b := &YearBalanceSheet{totalCurrentAssets: decimal.New(100, 0)}
+
+	// Act
+	_, err := b.CurrentRatio()
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotMeaningful)
 }
 
 func TestBalanceShortTermDebt(t *testing.T) {
 	// Arrange
 	m := YahooMockClient{}
-	ybs, _ := m.GetBalanceSheet("")
+	ybs, _ := m.GetBalanceSheet("AAPL")
 	bs := NewBalanceSheet(ybs)
 
 	// Act / Assert
-	assert.NotZero(t, bs.Y2018.ShortTermDebt())
-	assert.NotZero(t, bs.Y2019.ShortTermDebt())
-	assert.NotZero(t, bs.Y2020.ShortTermDebt())
-	assert.NotZero(t, bs.Y2021.ShortTermDebt())
+	assert.NotZero(t, bs.Year(2018).ShortTermDebt())
+	assert.NotZero(t, bs.Year(2019).ShortTermDebt())
+	assert.NotZero(t, bs.Year(2020).ShortTermDebt())
+	assert.NotZero(t, bs.Year(2021).ShortTermDebt())
 }
 
 func TestBalanceLongTermDebt(t *testing.T) {
 	// Arrange
 	m := YahooMockClient{}
-	ybs, _ := m.GetBalanceSheet("")
+	ybs, _ := m.GetBalanceSheet("AAPL")
 	bs := NewBalanceSheet(ybs)
 
 	// Act / Assert
-	assert.NotZero(t, bs.Y2018.LongTermDebt())
-	assert.NotZero(t, bs.Y2019.LongTermDebt())
-	assert.NotZero(t, bs.Y2020.LongTermDebt())
-	assert.NotZero(t, bs.Y2021.LongTermDebt())
+	assert.NotZero(t, bs.Year(2018).LongTermDebt())
+	assert.NotZero(t, bs.Year(2019).LongTermDebt())
+	assert.NotZero(t, bs.Year(2020).LongTermDebt())
+	assert.NotZero(t, bs.Year(2021).LongTermDebt())
 }
 
 func TestBalanceTotalAssets(t *testing.T) {
 	// Arrange
 	m := YahooMockClient{}
-	ybs, _ := m.GetBalanceSheet("")
+	ybs, _ := m.GetBalanceSheet("AAPL")
 	bs := NewBalanceSheet(ybs)
 
 	// Act / Assert
-	assert.NotZero(t, bs.Y2018.TotalAssets())
-	assert.NotZero(t, bs.Y2019.TotalAssets())
-	assert.NotZero(t, bs.Y2020.TotalAssets())
-	assert.NotZero(t, bs.Y2021.TotalAssets())
+	assert.NotZero(t, bs.Year(2018).TotalAssets())
+	assert.NotZero(t, bs.Year(2019).TotalAssets())
+	assert.NotZero(t, bs.Year(2020).TotalAssets())
+	assert.NotZero(t, bs.Year(2021).TotalAssets())
+}
+
+func TestBalanceSheetAnyFiscalCalendar(t *testing.T) {
+	// Arrange
+	payload := `{"data": [
+		{"index": "Total Assets", "1640908800000": 359268000000, "1672444800000": 365264000000},
+		{"index": "Total Current Assets", "1640908800000": 188143000000, "1672444800000": 164795000000, "1703980800000": null}
+	]}`
+
+	var ybs *YahooBalanceSheetV1
+	err := json.Unmarshal([]byte(payload), &ybs)
+
+	// Act
+	bs := NewBalanceSheet(ybs)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, bs.Len())
+	assert.Equal(t, time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC), bs.Ends()[1])
+	assert.Equal(t, int64(359268000000), bs.Year(2021).TotalAssets().Units())
+	assert.Equal(t, int64(164795000000), bs.Year(2022).TotalCurrentAssets().Units())
+}
+
+func TestBalanceLineItems(t *testing.T) {
+	// Arrange
+	m := YahooMockClient{}
+	ybs, _ := m.GetBalanceSheet("AAPL")
+
+	// Act
+	b := NewBalanceSheet(ybs).Year(2021)
+
+	// Assert
+	assert.Equal(t, int64(34940000000), b.Cash().Units())
+	assert.Equal(t, int64(27699000000), b.ShortTermInvestments().Units())
+	assert.Equal(t, int64(6580000000), b.Inventory().Units())
+	assert.Equal(t, int64(14111000000), b.OtherCurrentAssets().Units())
+	assert.Equal(t, int64(127877000000), b.LongTermInvestments().Units())
+	assert.Equal(t, int64(38762000000), b.OtherAssets().Units())
+	assert.Equal(t, int64(54763000000), b.AccountsPayable().Units())
+	assert.Equal(t, int64(53577000000), b.OtherCurrentLiabilities().Units())
+	assert.Equal(t, int64(43050000000), b.OtherLiabilities().Units())
+	assert.Equal(t, int64(57365000000), b.CommonStock().Units())
+	assert.Equal(t, int64(5562000000), b.RetainedEarnings().Units())
+	assert.Equal(t, int64(163000000), b.TreasuryStock().Units())
+	assert.Equal(t, int64(63090000000), b.ReportedNetTangibleAssets().Units())
+
+	// Apple reports no goodwill, intangibles or preferred stock
+	assert.Zero(t, b.GoodWill())
+	assert.Zero(t, b.IntangibleAssets())
+	assert.Zero(t, b.PreferredStock())
+}
+
+func TestBalanceWorkingCapital(t *testing.T) {
+	// Arrange
+	m := YahooMockClient{}
+	ybs, _ := m.GetBalanceSheet("AAPL")
+	b := NewBalanceSheet(ybs).Year(2021)
+
+	// Act
+	w := b.WorkingCapital()
+
+	// Assert
+	assert.Equal(t, int64(134836000000-125481000000), w.Units())
+}
+
+func TestBalanceNetTangibleAssets(t *testing.T) {
+	// Arrange
+	b := &YearBalanceSheet{totalShareholdersEquity: decimal.New(1000, 0), preferredStock: decimal.New(100, 0), goodWill: decimal.New(200, 0), intangibleAssets: decimal.New(50, 0)}
+
+	// Act
+	n := b.NetTangibleAssets()
+
+	// Assert
+	assert.Equal(t, int64(650), n.Units())
+}
+
+func TestBalanceLongTermDebtYearsToRepay(t *testing.T) {
+	// Arrange
+	b := &YearBalanceSheet{longTermDebt: decimal.New(300, 0)}
+
+	// Act
+	years, err := b.LongTermDebtYearsToRepay(NewMoney(100, ""))
+	_, lossErr := b.LongTermDebtYearsToRepay(NewMoney(-100, ""))
+	_, currencyErr := b.LongTermDebtYearsToRepay(NewMoney(100, "EUR"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "3", years.String())
+	assert.ErrorIs(t, lossErr, ErrNotMeaningful)
+	assert.ErrorIs(t, currencyErr, ErrCurrencyMismatch)
 }
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// CacheTTL is how long each type of response stays fresh in a Cache
+type CacheTTL struct {
+	// Statements change once a quarter at most
+	Statements time.Duration
+	// Quotes are the stock info, which carries the current price
+	Quotes time.Duration
+}
+
+// DefaultCacheTTL keeps statements for a week and quotes for 15 minutes
+func DefaultCacheTTL() CacheTTL {
+	return CacheTTL{Statements: 7 * 24 * time.Hour, Quotes: 15 * time.Minute}
+}
+
+// Cache is a directory of provider responses, laid out as <provider>/<SYMBOL>/<endpoint>.json. A symbol's
+// directory holds the same files the file provider reads
+type Cache struct {
+	Dir string
+	TTL CacheTTL
+	now func() time.Time
+}
+
+// CacheEntry is one cached response
+type CacheEntry struct {
+	Provider string
+	Symbol   string
+	Endpoint string
+	Fetched  time.Time
+	Size     int64
+	Fresh    bool
+}
+
+// cacheEndpoints are the cached responses, by file name, and whether each is a quote
+var cacheEndpoints = map[string]bool{
+	"income":              false,
+	"balance":             false,
+	"cash-flow":           false,
+	"quarterly-balance":   false,
+	"quarterly-cash-flow": false,
+	"stock":               true,
+}
+
+// NewCache returns a Cache in dir with the default TTLs
+func NewCache(dir string) *Cache {
+	return &Cache{Dir: dir, TTL: DefaultCacheTTL()}
+}
+
+// DefaultCacheDir is the finance directory of the user's cache directory, e.g. ~/.cache/finance
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "finance")
+}
+
+// clock returns the time responses are fetched and aged against
+func (c *Cache) clock() time.Time {
+	if c.now == nil {
+		return time.Now()
+	}
+
+	return c.now()
+}
+
+// ttl returns how long a response from endpoint stays fresh
+func (c *Cache) ttl(endpoint string) time.Duration {
+	if cacheEndpoints[endpoint] {
+		return c.TTL.Quotes
+	}
+
+	return c.TTL.Statements
+}
+
+// path returns the file of a cached response, ErrInvalidSymbol when symbol could name a path outside the cache
+func (c *Cache) path(provider, symbol, endpoint string) (string, error) {
+	s, err := checkSymbol(symbol)
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(c.Dir, provider, s, endpoint+".json"), nil
+}
+
+// read returns the body of the cached response, reporting false when it is missing, expired or not JSON
+func (c *Cache) read(provider, symbol, endpoint string) ([]byte, bool) {
+	path, err := c.path(provider, symbol, endpoint)
+
+	if err != nil {
+		return nil, false
+	}
+
+	info, err := os.Stat(path)
+
+	if err != nil || c.clock().Sub(info.ModTime()) >= c.ttl(endpoint) {
+		return nil, false
+	}
+
+	b, err := os.ReadFile(path)
+
+	if err != nil || !json.Valid(b) {
+		return nil, false
+	}
+
+	return b, true
+}
+
+// write stores the body of a response as it was sent, fetched now by the cache's clock
+func (c *Cache) write(provider, symbol, endpoint string, b []byte) error {
+	path, err := c.path(provider, symbol, endpoint)
+
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	if err := writeResponseFile(path, b, c.clock()); err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	return nil
+}
+
+// writeResponseFile stores the body of a response in path, modified at modTime. The file is replaced in one step
+// so readers never see half of it
+func writeResponseFile(path string, b []byte, modTime time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := os.Chtimes(tmp.Name(), modTime, modTime); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Entries returns every cached response, ordered by provider, symbol and endpoint
+func (c *Cache) Entries() ([]CacheEntry, error) {
+	x := []CacheEntry{}
+
+	err := filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == c.Dir {
+				return filepath.SkipDir
+			}
+
+			return err
+		}
+
+		rel, _ := filepath.Rel(c.Dir, path)
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+
+		if d.IsDir() || len(parts) != 3 || !strings.HasSuffix(parts[2], ".json") {
+			return nil
+		}
+
+		info, err := d.Info()
+
+		if err != nil {
+			return err
+		}
+
+		e := CacheEntry{
+			Provider: parts[0],
+			Symbol:   parts[1],
+			Endpoint: strings.TrimSuffix(parts[2], ".json"),
+			Fetched:  info.ModTime(),
+			Size:     info.Size(),
+		}
+
+		e.Fresh = c.clock().Sub(e.Fetched) < c.ttl(e.Endpoint)
+
+		x = append(x, e)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+
+	sort.Slice(x, func(i, j int) bool {
+		if x[i].Provider != x[j].Provider {
+			return x[i].Provider < x[j].Provider
+		}
+
+		if x[i].Symbol != x[j].Symbol {
+			return x[i].Symbol < x[j].Symbol
+		}
+
+		return x[i].Endpoint < x[j].Endpoint
+	})
+
+	return x, nil
+}
+
+// Clear removes the cached responses of symbol from every provider, or the whole cache when symbol is empty
+func (c *Cache) Clear(symbol string) error {
+	if symbol == "" {
+		if err := os.RemoveAll(c.Dir); err != nil {
+			return fmt.Errorf("cache: %w", err)
+		}
+
+		return nil
+	}
+
+	s, err := checkSymbol(symbol)
+
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	providers, err := os.ReadDir(c.Dir)
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+
+	for _, p := range providers {
+		if !p.IsDir() {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(c.Dir, p.Name(), s)); err != nil {
+			return fmt.Errorf("cache: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// PrintCacheEntries writes every cached response as a table
+func PrintCacheEntries(w io.Writer, x []CacheEntry) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Provider\tSymbol\tEndpoint\tFetched\tBytes\tFresh")
+
+	for _, e := range x {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%t\n", e.Provider, e.Symbol, e.Endpoint, e.Fetched.Format(time.RFC3339), e.Size, e.Fresh)
+	}
+
+	tw.Flush()
+}
+
+// CachingProvider serves responses from Cache, fetching from Provider when they are missing or expired
+type CachingProvider struct {
+	Provider FundamentalsProvider
+	// Name keeps the responses of each provider apart in the cache
+	Name  string
+	Cache *Cache
+}
+
+func (c *CachingProvider) GetIncomeStatement(symbol string) (*YahooIncomeStatementV15, error) {
+	return cached[YahooIncomeStatementV15](c, symbol, "income")
+}
+
+func (c *CachingProvider) GetBalanceSheet(symbol string) (*YahooBalanceSheetV1, error) {
+	return cached[YahooBalanceSheetV1](c, symbol, "balance")
+}
+
+func (c *CachingProvider) GetCashFlow(symbol string) (*YahooCashFlowV1, error) {
+	return cached[YahooCashFlowV1](c, symbol, "cash-flow")
+}
+
+func (c *CachingProvider) GetStockInfo(symbol string) (*YahooStockInfo, error) {
+	return cached[YahooStockInfo](c, symbol, "stock")
+}
+
+func (c *CachingProvider) GetQuarterlyBalanceSheet(symbol string) (*YahooBalanceSheetV1, error) {
+	return cached[YahooBalanceSheetV1](c, symbol, "quarterly-balance")
+}
+
+func (c *CachingProvider) GetQuarterlyCashFlow(symbol string) (*YahooCashFlowV1, error) {
+	return cached[YahooCashFlowV1](c, symbol, "quarterly-cash-flow")
+}
+
+// GetRawResponse returns the fresh cached body of the endpoint response, or fetches and caches it as the provider
+// sent it, so fields the statements don't model yet are kept. Failing to write the cache doesn't fail the fetch
+func (c *CachingProvider) GetRawResponse(symbol, endpoint string) ([]byte, error) {
+	if b, ok := c.Cache.read(c.Name, symbol, endpoint); ok {
+		return b, nil
+	}
+
+	r, ok := c.Provider.(RawProvider)
+
+	if !ok {
+		return nil, fmt.Errorf("cache: provider %T has no raw responses to cache", c.Provider)
+	}
+
+	b, err := r.GetRawResponse(symbol, endpoint)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Cache.write(c.Name, symbol, endpoint, b); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	return b, nil
+}
+
+// cached returns the response of endpoint from the cache, or fetched and cached, decoded
+func cached[T any](c *CachingProvider, symbol, endpoint string) (*T, error) {
+	b, err := c.GetRawResponse(symbol, endpoint)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse[T](b, endpoint)
+}
+
+// decodeResponse decodes the body of an endpoint response, rejecting null which would otherwise become an empty
+// statement
+func decodeResponse[T any](b []byte, endpoint string) (*T, error) {
+	var x *T
+
+	if err := json.Unmarshal(b, &x); err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", endpoint, ErrMalformedPayload, err)
+	}
+
+	if x == nil {
+		return nil, fmt.Errorf("%s: empty response: %w", endpoint, ErrMalformedPayload)
+	}
+
+	return x, nil
+}
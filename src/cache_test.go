@@ -0,0 +1,151 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// yahooTestServer stands in for the RapidAPI endpoints, serving the bundled Apple responses and counting the hits per path
+func yahooTestServer(t *testing.T) (*YahooAPIClient, map[string]int) {
+	files := map[string]string{
+		"/qu/quote/AAPL/income-statement": "./json/fixtures/yahoo-rapidapi/AAPL/income.json",
+		"/balance-sheet":                  "./json/fixtures/yahoo-rapidapi/AAPL/balance.json",
+		"/cashflow":                       "./json/fixtures/yahoo-rapidapi/AAPL/cash-flow.json",
+		"/quarterly-balance-sheet":        "./json/fixtures/yahoo-rapidapi/AAPL/quarterly-balance.json",
+		"/quarterly-cashflow":             "./json/fixtures/yahoo-rapidapi/AAPL/quarterly-cash-flow.json",
+		"/stock-info":                     "./json/fixtures/yahoo-rapidapi/AAPL/stock.json",
+	}
+
+	hits := map[string]int{}
+	var mu sync.Mutex
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+
+		b, err := os.ReadFile(files[r.URL.Path])
+
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write(b)
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return &YahooAPIClient{Origin: srv.URL, StockOrigin: srv.URL}, hits
+}
+
+func TestCachingProviderServesFromCache(t *testing.T) {
+	// Arrange
+	y, hits := yahooTestServer(t)
+	c := &CachingProvider{Provider: y, Name: "yahoo-rapidapi", Cache: NewCache(t.TempDir())}
+
+	// Act
+	first, err := LoadFundamentals(c, "AAPL")
+	second, _ := LoadFundamentals(c, "AAPL")
+	entries, _ := c.Cache.Entries()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits["/qu/quote/AAPL/income-statement"])
+	assert.Equal(t, 1, hits["/balance-sheet"])
+	assert.Equal(t, 1, hits["/cashflow"])
+	assert.Equal(t, 1, hits["/stock-info"])
+	assert.Equal(t, ScoreValue(first, nil).Total, ScoreValue(second, nil).Total)
+	assert.Len(t, entries, 4)
+	assert.Equal(t, "AAPL", entries[0].Symbol)
+	assert.True(t, entries[0].Fresh)
+}
+
+func TestCachingProviderKeepsRawResponses(t *testing.T) {
+	// Arrange
+	y, _ := yahooTestServer(t)
+	c := &CachingProvider{Provider: y, Name: "yahoo-rapidapi", Cache: NewCache(t.TempDir())}
+	sent, _ := os.ReadFile("./json/fixtures/yahoo-rapidapi/AAPL/stock.json")
+
+	// Act
+	_, err := c.GetStockInfo("AAPL")
+	b, _ := os.ReadFile(filepath.Join(c.Cache.Dir, "yahoo-rapidapi", "AAPL", "stock.json"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, sent, b, "fields the stock info doesn't model, such as address1, are kept")
+}
+
+func TestCachingProviderExpiresQuotesFirst(t *testing.T) {
+	// Arrange
+	y, hits := yahooTestServer(t)
+	cache := NewCache(t.TempDir())
+	c := &CachingProvider{Provider: y, Name: "yahoo-rapidapi", Cache: cache}
+	LoadFundamentals(c, "AAPL")
+	cache.now = func() time.Time { return time.Now().Add(time.Hour) }
+
+	// Act
+	_, err := LoadFundamentals(c, "AAPL")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, hits["/stock-info"])
+	assert.Equal(t, 1, hits["/qu/quote/AAPL/income-statement"])
+}
+
+func TestCacheClear(t *testing.T) {
+	// Arrange
+	y, _ := yahooTestServer(t)
+	c := &CachingProvider{Provider: y, Name: "yahoo-rapidapi", Cache: NewCache(t.TempDir())}
+	LoadFundamentals(c, "AAPL")
+
+	// Act
+	err := c.Cache.Clear("aapl")
+	entries, _ := c.Cache.Entries()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestCacheClearRejectsPaths(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	outside := filepath.Join(dir, "outside.json")
+	os.WriteFile(outside, []byte("{}"), 0644)
+	c := NewCache(filepath.Join(dir, "cache", "finance"))
+	os.MkdirAll(filepath.Join(c.Dir, "yahoo-rapidapi"), 0755)
+
+	// Act
+	errs := []error{c.Clear(".."), c.Clear("../.."), c.Clear("AAPL/../.."), c.Clear("/tmp"), c.Clear(".")}
+	writeErr := c.write("yahoo-rapidapi", "../AAPL", "stock", []byte("{}"))
+	tickerErr := c.Clear("brk.b")
+
+	// Assert
+	for _, err := range errs {
+		assert.ErrorIs(t, err, ErrInvalidSymbol)
+	}
+	assert.ErrorIs(t, writeErr, ErrInvalidSymbol)
+	assert.NoError(t, tickerErr)
+	assert.FileExists(t, outside)
+	assert.DirExists(t, filepath.Join(c.Dir, "yahoo-rapidapi"))
+}
+
+func TestCacheEntriesOfMissingDir(t *testing.T) {
+	// Arrange
+	c := NewCache(t.TempDir() + "/missing")
+
+	// Act
+	entries, err := c.Entries()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -1 +1,247 @@
 package main
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CashFlowStatement holds every fiscal year of cash flow statements that was loaded
+type CashFlowStatement struct {
+	Series[*YearCashFlowStatement]
+}
+
+type YearCashFlowStatement struct {
+	Year int
+	End  time.Time
+	// Currency the amounts are reported in, each line item is an exact amount of it
+	Currency                              string
+	netEarnings                           decimal.Decimal
+	depreciation                          decimal.Decimal
+	changeToNetEarnings                   decimal.Decimal
+	changeToAccountReceivables            decimal.Decimal
+	changeToLiabilities                   decimal.Decimal
+	changeToInventory                     decimal.Decimal
+	changeToOperatingActivities           decimal.Decimal
+	totalCashFromOperatingActivities      decimal.Decimal
+	capitalExpenditures                   decimal.Decimal
+	investments                           decimal.Decimal
+	otherCashflowsFromInvestingActivities decimal.Decimal
+	totalCashflowsFromInvestingActivities decimal.Decimal
+	dividendsPaid                         decimal.Decimal
+	netBorrowings                         decimal.Decimal
+	issuanceOfStock                       decimal.Decimal
+	repurchaseOfStock                     decimal.Decimal
+	otherCashflowsFromFinancingActivities decimal.Decimal
+	totalCashFromFinancingActivities      decimal.Decimal
+	changeInCash                          decimal.Decimal
+}
+
+// cashFlowItems maps a normalised Yahoo line item name (upper case, no spaces) onto its YearCashFlowStatement field
+var cashFlowItems = map[string]func(c *YearCashFlowStatement, v int64){
+	"NETINCOME":                             func(c *YearCashFlowStatement, v int64) { c.netEarnings = decimal.New(v, 0) },
+	"DEPRECIATION":                          func(c *YearCashFlowStatement, v int64) { c.depreciation = decimal.New(v, 0) },
+	"CHANGETONETINCOME":                     func(c *YearCashFlowStatement, v int64) { c.changeToNetEarnings = decimal.New(v, 0) },
+	"CHANGETOACCOUNTRECEIVABLES":            func(c *YearCashFlowStatement, v int64) { c.changeToAccountReceivables = decimal.New(v, 0) },
+	"CHANGETOLIABILITIES":                   func(c *YearCashFlowStatement, v int64) { c.changeToLiabilities = decimal.New(v, 0) },
+	"CHANGETOINVENTORY":                     func(c *YearCashFlowStatement, v int64) { c.changeToInventory = decimal.New(v, 0) },
+	"CHANGETOOPERATINGACTIVITIES":           func(c *YearCashFlowStatement, v int64) { c.changeToOperatingActivities = decimal.New(v, 0) },
+	"TOTALCASHFROMOPERATINGACTIVITIES":      func(c *YearCashFlowStatement, v int64) { c.totalCashFromOperatingActivities = decimal.New(v, 0) },
+	"CAPITALEXPENDITURES":                   func(c *YearCashFlowStatement, v int64) { c.capitalExpenditures = decimal.New(v, 0) },
+	"INVESTMENTS":                           func(c *YearCashFlowStatement, v int64) { c.investments = decimal.New(v, 0) },
+	"OTHERCASHFLOWSFROMINVESTINGACTIVITIES": func(c *YearCashFlowStatement, v int64) { c.otherCashflowsFromInvestingActivities = decimal.New(v, 0) },
+	"TOTALCASHFLOWSFROMINVESTINGACTIVITIES": func(c *YearCashFlowStatement, v int64) { c.totalCashflowsFromInvestingActivities = decimal.New(v, 0) },
+	"DIVIDENDSPAID":                         func(c *YearCashFlowStatement, v int64) { c.dividendsPaid = decimal.New(v, 0) },
+	"NETBORROWINGS":                         func(c *YearCashFlowStatement, v int64) { c.netBorrowings = decimal.New(v, 0) },
+	"ISSUANCEOFSTOCK":                       func(c *YearCashFlowStatement, v int64) { c.issuanceOfStock = decimal.New(v, 0) },
+	"REPURCHASEOFSTOCK":                     func(c *YearCashFlowStatement, v int64) { c.repurchaseOfStock = decimal.New(v, 0) },
+	"OTHERCASHFLOWSFROMFINANCINGACTIVITIES": func(c *YearCashFlowStatement, v int64) { c.otherCashflowsFromFinancingActivities = decimal.New(v, 0) },
+	"TOTALCASHFROMFINANCINGACTIVITIES":      func(c *YearCashFlowStatement, v int64) { c.totalCashFromFinancingActivities = decimal.New(v, 0) },
+	"CHANGEINCASH":                          func(c *YearCashFlowStatement, v int64) { c.changeInCash = decimal.New(v, 0) },
+}
+
+// NewCashFlowStatement creates a CashFlowStatement from Yahoo API data
+func NewCashFlowStatement(ycf *YahooCashFlowV1) *CashFlowStatement {
+	cf := CashFlowStatement{}
+
+	for _, item := range ycf.Root {
+		name := normaliseLineItem(item.Name)
+
+		set, ok := cashFlowItems[name]
+
+		if !ok {
+			continue
+		}
+
+		for end, v := range item.Values {
+			y, ok := cf.Get(end)
+
+			if !ok {
+				y = &YearCashFlowStatement{Year: end.Year(), End: end}
+				cf.Set(end, y)
+			}
+
+			set(y, v)
+		}
+	}
+
+	return &cf
+}
+
+// SetCurrency sets the currency of every year, Yahoo reports it with the stock rather than the statement
+func (C *CashFlowStatement) SetCurrency(currency string) {
+	for _, y := range C.Values() {
+		y.Currency = currency
+	}
+}
+
+// NetEarnings as reported at the top of the cash flow statement
+func (c *YearCashFlowStatement) NetEarnings() Money {
+	return c.money(c.netEarnings)
+}
+
+// Depreciation (and amortisation) added back to net earnings
+func (c *YearCashFlowStatement) Depreciation() Money {
+	return c.money(c.depreciation)
+}
+
+// ChangeToNetEarnings (other non-cash items)
+func (c *YearCashFlowStatement) ChangeToNetEarnings() Money {
+	return c.money(c.changeToNetEarnings)
+}
+
+// ChangeToAccountReceivables
+func (c *YearCashFlowStatement) ChangeToAccountReceivables() Money {
+	return c.money(c.changeToAccountReceivables)
+}
+
+// ChangeToLiabilities
+func (c *YearCashFlowStatement) ChangeToLiabilities() Money {
+	return c.money(c.changeToLiabilities)
+}
+
+// ChangeToInventory
+func (c *YearCashFlowStatement) ChangeToInventory() Money {
+	return c.money(c.changeToInventory)
+}
+
+// ChangeToOperatingActivities
+func (c *YearCashFlowStatement) ChangeToOperatingActivities() Money {
+	return c.money(c.changeToOperatingActivities)
+}
+
+// TotalCashFromOperatingActivities aka Operating Cash Flow
+func (c *YearCashFlowStatement) TotalCashFromOperatingActivities() Money {
+	return c.money(c.totalCashFromOperatingActivities)
+}
+
+// CapitalExpenditures (negative, cash spent on property, plant and equipment)
+func (c *YearCashFlowStatement) CapitalExpenditures() Money {
+	return c.money(c.capitalExpenditures)
+}
+
+// Investments
+func (c *YearCashFlowStatement) Investments() Money {
+	return c.money(c.investments)
+}
+
+// OtherCashflowsFromInvestingActivities
+func (c *YearCashFlowStatement) OtherCashflowsFromInvestingActivities() Money {
+	return c.money(c.otherCashflowsFromInvestingActivities)
+}
+
+// TotalCashflowsFromInvestingActivities
+func (c *YearCashFlowStatement) TotalCashflowsFromInvestingActivities() Money {
+	return c.money(c.totalCashflowsFromInvestingActivities)
+}
+
+// DividendsPaid (negative)
+func (c *YearCashFlowStatement) DividendsPaid() Money {
+	return c.money(c.dividendsPaid)
+}
+
+// NetBorrowings
+func (c *YearCashFlowStatement) NetBorrowings() Money {
+	return c.money(c.netBorrowings)
+}
+
+// IssuanceOfStock
+func (c *YearCashFlowStatement) IssuanceOfStock() Money {
+	return c.money(c.issuanceOfStock)
+}
+
+// RepurchaseOfStock (negative, cash spent buying back shares)
+func (c *YearCashFlowStatement) RepurchaseOfStock() Money {
+	return c.money(c.repurchaseOfStock)
+}
+
+// OtherCashflowsFromFinancingActivities
+func (c *YearCashFlowStatement) OtherCashflowsFromFinancingActivities() Money {
+	return c.money(c.otherCashflowsFromFinancingActivities)
+}
+
+// TotalCashFromFinancingActivities
+func (c *YearCashFlowStatement) TotalCashFromFinancingActivities() Money {
+	return c.money(c.totalCashFromFinancingActivities)
+}
+
+// ChangeInCash
+func (c *YearCashFlowStatement) ChangeInCash() Money {
+	return c.money(c.changeInCash)
+}
+
+// FreeCashFlow (TotalCashFromOperatingActivities - CapitalExpenditures)
+func (c *YearCashFlowStatement) FreeCashFlow() Money {
+	return c.money(c.totalCashFromOperatingActivities.Add(c.capitalExpenditures))
+}
+
+// CapitalExpendituresToNetEarnings (CapitalExpenditures / NetEarnings), below 0.5 suggests a durable advantage
+func (c *YearCashFlowStatement) CapitalExpendituresToNetEarnings() (decimal.Decimal, error) {
+	return Ratio(c.money(c.capitalExpenditures.Neg()), c.NetEarnings())
+}
+
+// money returns an amount of the cash flow statement in its currency
+func (c *YearCashFlowStatement) money(v decimal.Decimal) Money {
+	return Money{Amount: v, Currency: c.Currency}
+}
+
+// Buybacks returns the cash spent repurchasing stock, net of stock issued
+func (c *YearCashFlowStatement) Buybacks() Money {
+	return c.money(c.repurchaseOfStock.Add(c.issuanceOfStock).Neg())
+}
+
+// FreeCashFlowMean returns the mean FreeCashFlow over every loaded year
+func (C *CashFlowStatement) FreeCashFlowMean() float64 {
+	x := []float64{}
+
+	for _, y := range C.Values() {
+		x = append(x, y.FreeCashFlow().Float64())
+	}
+
+	return mean(x)
+}
+
+// CapitalExpendituresToNetEarningsMean returns the mean CapitalExpendituresToNetEarnings over every loaded year
+// with net earnings
+func (C *CashFlowStatement) CapitalExpendituresToNetEarningsMean() float64 {
+	x := []float64{}
+
+	for _, y := range C.Values() {
+		if v, err := ratioFloat(y.CapitalExpendituresToNetEarnings()); err == nil {
+			x = append(x, v)
+		}
+	}
+
+	return mean(x)
+}
+
+// BuybacksTotal returns the net cash spent on buybacks over every loaded year
+func (C *CashFlowStatement) BuybacksTotal() Money {
+	sum := Money{}
+
+	for _, y := range C.Values() {
+		sum = Money{Amount: sum.Amount.Add(y.Buybacks().Amount), Currency: y.Currency}
+	}
+
+	return sum
+}
@@ -1 +1,56 @@
 package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCashFlowOperatingActivities(t *testing.T) {
+	// Arrange
+	m := YahooMockClient{}
+	ycf, _ := m.GetCashFlow("AAPL")
+	cf := NewCashFlowStatement(ycf)
+
+	// Act / Assert
+	assert.Equal(t, 4, cf.Len())
+	assert.Equal(t, int64(77434000000), cf.Year(2018).TotalCashFromOperatingActivities().Units())
+	assert.Equal(t, int64(104038000000), cf.Year(2021).TotalCashFromOperatingActivities().Units())
+}
+
+func TestCashFlowFreeCashFlow(t *testing.T) {
+	// Arrange
+	m := YahooMockClient{}
+	ycf, _ := m.GetCashFlow("AAPL")
+	cf := NewCashFlowStatement(ycf)
+
+	// Act / Assert
+	assert.Equal(t, int64(104038000000-11085000000), cf.Year(2021).FreeCashFlow().Units())
+	assert.Equal(t, int64(69391000000-10495000000), cf.Year(2019).FreeCashFlow().Units())
+}
+
+func TestCashFlowCapitalExpendituresToNetEarnings(t *testing.T) {
+	// Arrange
+	m := YahooMockClient{}
+	ycf, _ := m.GetCashFlow("AAPL")
+	cf := NewCashFlowStatement(ycf)
+
+	// Act
+	r, err := ratioFloat(cf.Year(2021).CapitalExpendituresToNetEarnings())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.InDelta(t, 11085.0/94680.0, r, 0.0001)
+	assert.Less(t, cf.CapitalExpendituresToNetEarningsMean(), 0.5)
+}
+
+func TestCashFlowBuybacks(t *testing.T) {
+	// Arrange
+	m := YahooMockClient{}
+	ycf, _ := m.GetCashFlow("AAPL")
+	cf := NewCashFlowStatement(ycf)
+
+	// Act / Assert
+	assert.Equal(t, int64(92527000000-1105000000), cf.Year(2021).Buybacks().Units())
+	assert.Equal(t, int64(75265000000+69714000000+75992000000+92527000000-669000000-781000000-880000000-1105000000), cf.BuybacksTotal().Units())
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// CSVFileClient reads statements typed up from annual reports, for companies no API covers.
+//
+// Each symbol is either a directory <Dir>/<SYMBOL>/ holding income.csv, balance.csv, cash-flow.csv
+// and stock.csv, or a workbook <Dir>/<SYMBOL>.xlsx with sheets of the same names.
+//
+// Statements have one line item per row and one fiscal year per column, the header row holds
+// the fiscal year end dates:
+//
+//	index,2020-12-31,2021-12-31
+//	Total Revenue,1200000,1350000
+//	Cost Of Revenue,700000,760000
+//
+// Line items are named like the Yahoo "index" field (case and spaces are ignored), see
+// csvIncomeRows, csvBalanceSheetRows and csvCashFlowRows. stock.csv holds index,value rows.
+type CSVFileClient struct {
+	Dir string
+}
+
+// ErrUnknownLineItem is returned for a row that isn't a known line item of the statement
+var ErrUnknownLineItem = errors.New("unknown line item")
+
+// ErrMissingLineItem is returned when a required row is absent
+var ErrMissingLineItem = errors.New("missing required line item")
+
+// csvRows lists the line items of a statement, and which of them must be present
+type csvRows struct {
+	known    []string
+	required []string
+}
+
+var csvIncomeRows = csvRows{
+	known: []string{
+		"Total Revenue", "Cost Of Revenue", "Gross Profit", "Research Development", "Selling General Administrative",
+		"Interest Expense", "Income Before Tax", "Income Tax Expense", "Net Income", "Diluted Average Shares",
+	},
+	required: []string{"Total Revenue", "Cost Of Revenue", "Net Income"},
+}
+
+var csvBalanceSheetRows = csvRows{
+	known: []string{
+		"Cash", "Short Term Investments", "Net Receivables", "Inventory", "Other Current Assets", "Total Current Assets",
+		"Long Term Investments", "Property Plant Equipment", "Good Will", "Intangible Assets", "Other Assets",
+		"Deferred Long Term Asset Charges", "Net Tangible Assets", "Total Assets", "Accounts Payable",
+		"Short Long Term Debt", "Other Current Liab", "Total Current Liabilities", "Long Term Debt",
+		"Deferred Long Term Liab", "Minority Interest", "Other Liab", "Total Liab", "Preferred Stock", "Common Stock",
+		"Capital Surplus", "Retained Earnings", "Treasury Stock", "Other Stockholder Equity", "Total Stockholder Equity",
+	},
+	required: []string{"Total Assets", "Total Liab", "Total Current Assets", "Total Current Liabilities", "Total Stockholder Equity"},
+}
+
+var csvCashFlowRows = csvRows{
+	known: []string{
+		"Net Income", "Depreciation", "Change To Netincome", "Change To Account Receivables", "Change To Liabilities",
+		"Change To Inventory", "Change To Operating Activities", "Total Cash From Operating Activities",
+		"Capital Expenditures", "Investments", "Other Cashflows From Investing Activities",
+		"Total Cashflows From Investing Activities", "Dividends Paid", "Net Borrowings", "Issuance Of Stock",
+		"Repurchase Of Stock", "Other Cashflows From Financing Activities", "Total Cash From Financing Activities",
+		"Change In Cash",
+	},
+	required: []string{"Total Cash From Operating Activities", "Capital Expenditures"},
+}
+
+var csvStockRows = csvRows{
+	known:    []string{"Shares Outstanding", "Currency", "Country"},
+	required: []string{"Shares Outstanding"},
+}
+
+// csvRow is one line item with its value per fiscal year end
+type csvRow struct {
+	name   string
+	values map[time.Time]int64
+}
+
+func (c *CSVFileClient) GetIncomeStatement(symbol string) (*YahooIncomeStatementV15, error) {
+	rows, err := c.statement(symbol, "income", csvIncomeRows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ends := map[time.Time]*YahooIncomeStatementHistory{}
+	y := &YahooIncomeStatementV15{}
+
+	for _, row := range rows {
+		for end, v := range row.values {
+			h, ok := ends[end]
+
+			if !ok {
+				h = &YahooIncomeStatementHistory{EndDate: YahooIncomeStatementItem{Raw: end.Unix(), Fmt: end.Format("2006-01-02")}}
+				ends[end] = h
+			}
+
+			item := YahooIncomeStatementItem{Raw: v, Fmt: fmt.Sprint(v)}
+
+			switch normaliseLineItem(row.name) {
+			case "TOTALREVENUE":
+				h.TotalRevenue = item
+			case "COSTOFREVENUE":
+				h.CostOfRevenue = item
+			case "GROSSPROFIT":
+				h.GrossProfit = item
+			case "RESEARCHDEVELOPMENT":
+				h.ResearchDevelopment = item
+			case "SELLINGGENERALADMINISTRATIVE":
+				h.SellingGeneralAdministrative = item
+			case "INTERESTEXPENSE":
+				h.InterestExpense = item
+			case "INCOMEBEFORETAX":
+				h.IncomeBeforeTax = item
+			case "INCOMETAXEXPENSE":
+				h.IncomeTaxExpense = item
+			case "NETINCOME":
+				h.NetEarnings = item
+			case "DILUTEDAVERAGESHARES":
+				h.DilutedAverageShares = item
+			}
+		}
+	}
+
+	for _, h := range ends {
+		y.Root.IncomeStatementHistory = append(y.Root.IncomeStatementHistory, *h)
+	}
+
+	return y, nil
+}
+
+func (c *CSVFileClient) GetBalanceSheet(symbol string) (*YahooBalanceSheetV1, error) {
+	rows, err := c.statement(symbol, "balance", csvBalanceSheetRows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	x := &YahooBalanceSheetV1{}
+
+	for _, row := range rows {
+		x.Root = append(x.Root, YahooBalanceItem{Name: row.name, Values: row.values})
+	}
+
+	return x, nil
+}
+
+func (c *CSVFileClient) GetCashFlow(symbol string) (*YahooCashFlowV1, error) {
+	rows, err := c.statement(symbol, "cash-flow", csvCashFlowRows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	x := &YahooCashFlowV1{}
+
+	for _, row := range rows {
+		x.Root = append(x.Root, YahooCashItem{Name: row.name, Values: row.values})
+	}
+
+	return x, nil
+}
+
+func (c *CSVFileClient) GetStockInfo(symbol string) (*YahooStockInfo, error) {
+	records, source, err := c.records(symbol, "stock")
+
+	if err != nil {
+		return nil, err
+	}
+
+	x := &YahooStockInfo{}
+	seen := map[string]bool{}
+
+	for i, r := range records {
+		// Header
+		if i == 0 {
+			continue
+		}
+
+		if len(r) < 2 {
+			return nil, fmt.Errorf("%s line %d: expected index,value", source, i+1)
+		}
+
+		if !csvStockRows.knows(r[0]) {
+			return nil, fmt.Errorf("%s line %d: %w %q", source, i+1, ErrUnknownLineItem, r[0])
+		}
+
+		seen[normaliseLineItem(r[0])] = true
+
+		// The currency every statement is reported in, e.g. USD
+		if normaliseLineItem(r[0]) == "CURRENCY" {
+			x.Root.FinancialCurrency = strings.ToUpper(strings.TrimSpace(r[1]))
+			continue
+		}
+
+		// The country whose statutory tax rate applies, named as Yahoo names it, e.g. United States
+		if normaliseLineItem(r[0]) == "COUNTRY" {
+			x.Root.Country = strings.TrimSpace(r[1])
+			continue
+		}
+
+		v, err := parseStatementValue(r[1])
+
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", source, i+1, err)
+		}
+
+		x.Root.SharesOutstanding = v
+	}
+
+	if err := csvStockRows.check(seen, source); err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// statement reads and validates the named statement of symbol
+func (c *CSVFileClient) statement(symbol string, name string, known csvRows) ([]csvRow, error) {
+	records, source, err := c.records(symbol, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s: empty statement", source)
+	}
+
+	// Header row holds the fiscal year ends
+	ends := []time.Time{}
+
+	for _, h := range records[0][1:] {
+		end, err := time.Parse("2006-01-02", strings.TrimSpace(h))
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: header %q is not a fiscal year end (YYYY-MM-DD)", source, h)
+		}
+
+		ends = append(ends, end)
+	}
+
+	rows := []csvRow{}
+	seen := map[string]bool{}
+
+	for i, r := range records[1:] {
+		line := i + 2
+
+		if len(r) == 0 || strings.TrimSpace(r[0]) == "" {
+			continue
+		}
+
+		if !known.knows(r[0]) {
+			return nil, fmt.Errorf("%s line %d: %w %q", source, line, ErrUnknownLineItem, r[0])
+		}
+
+		row := csvRow{name: strings.TrimSpace(r[0]), values: map[time.Time]int64{}}
+
+		for j, cell := range r[1:] {
+			if j >= len(ends) {
+				return nil, fmt.Errorf("%s line %d: more values than fiscal years", source, line)
+			}
+
+			// Not reported that year
+			if strings.TrimSpace(cell) == "" {
+				continue
+			}
+
+			v, err := parseStatementValue(cell)
+
+			if err != nil {
+				return nil, fmt.Errorf("%s line %d: %s: %w", source, line, row.name, err)
+			}
+
+			row.values[ends[j]] = v
+		}
+
+		rows = append(rows, row)
+		seen[normaliseLineItem(row.name)] = true
+	}
+
+	if err := known.check(seen, source); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// records returns the cells of the named statement from <SYMBOL>.xlsx, or failing that <SYMBOL>/<name>.csv
+func (c *CSVFileClient) records(symbol string, name string) ([][]string, string, error) {
+	symbol = strings.ToUpper(symbol)
+
+	workbook := filepath.Join(c.Dir, symbol+".xlsx")
+
+	if _, err := os.Stat(workbook); err == nil {
+		f, err := excelize.OpenFile(workbook)
+
+		if err != nil {
+			return nil, workbook, err
+		}
+
+		defer f.Close()
+
+		records, err := f.GetRows(name)
+
+		if err != nil {
+			return nil, workbook, fmt.Errorf("%s: sheet %q: %w", workbook, name, err)
+		}
+
+		return records, fmt.Sprintf("%s[%s]", workbook, name), nil
+	}
+
+	source := filepath.Join(c.Dir, symbol, name+".csv")
+
+	f, err := os.Open(source)
+
+	if err != nil {
+		return nil, source, err
+	}
+
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	records, err := r.ReadAll()
+
+	if err != nil {
+		return nil, source, fmt.Errorf("%s: %w", source, err)
+	}
+
+	return records, source, nil
+}
+
+func (k csvRows) knows(name string) bool {
+	for _, x := range k.known {
+		if normaliseLineItem(x) == normaliseLineItem(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (k csvRows) check(seen map[string]bool, source string) error {
+	missing := []string{}
+
+	for _, x := range k.required {
+		if !seen[normaliseLineItem(x)] {
+			missing = append(missing, x)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%s: %w: %s", source, ErrMissingLineItem, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// normaliseLineItem upper cases a line item name and drops spaces, so "Total Liab" and "totalLiab" match
+func normaliseLineItem(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(name), " ", ""))
+}
+
+// parseStatementValue reads a whole currency amount, allowing thousands separators and (accounting) negatives
+func parseStatementValue(s string) (int64, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+
+	if negative {
+		s = s[1 : len(s)-1]
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a whole number", s)
+	}
+
+	if negative {
+		v = -v
+	}
+
+	return v, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestCSVIncomeStatement(t *testing.T) {
+	// Arrange
+	c := &CSVFileClient{Dir: "./json/csv"}
+	y, err := c.GetIncomeStatement("acme")
+	s, _ := c.GetStockInfo("acme")
+
+	// Act
+	x := NewIncomeStatement(y, s)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, x.Len())
+	assert.Equal(t, int64(1200000), x.Year(2019).TotalRevenue().Units())
+	assert.Equal(t, int64(-14000), x.Year(2020).InterestExpense().Units())
+	assert.Equal(t, int64(1000000), x.Year(2021).SharesOutstanding())
+	assert.Equal(t, int64(1080000), x.Year(2019).SharesOutstanding())
+}
+
+func TestCSVBalanceSheetAndCashFlow(t *testing.T) {
+	// Arrange
+	c := &CSVFileClient{Dir: "./json/csv"}
+
+	// Act
+	f, err := LoadFundamentals(c, "ACME")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", f.Currency())
+	assert.Equal(t, "United States", f.Stock.Root.Country)
+	assert.Equal(t, "USD", f.Balance.Year(2021).Currency)
+	assert.Equal(t, int64(1041000), f.Balance.Year(2021).TotalAssets().Units())
+	assert.Equal(t, int64(731000), f.Balance.Year(2021).TotalShareholdersEquity().Units())
+	assert.Equal(t, int64(362000-65000), f.CashFlow.Year(2021).FreeCashFlow().Units())
+}
+
+func TestCSVUnknownLineItem(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "ACME"), 0755)
+	os.WriteFile(filepath.Join(dir, "ACME", "income.csv"), []byte("index,2021-12-31\nTotal Revenue,10\nTurnover,10\n"), 0644)
+	c := &CSVFileClient{Dir: dir}
+
+	// Act
+	_, err := c.GetIncomeStatement("ACME")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrUnknownLineItem)
+	assert.Contains(t, err.Error(), "line 3")
+	assert.Contains(t, err.Error(), "Turnover")
+}
+
+func TestCSVMissingLineItem(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "ACME"), 0755)
+	os.WriteFile(filepath.Join(dir, "ACME", "income.csv"), []byte("index,2021-12-31\nTotal Revenue,10\n"), 0644)
+	c := &CSVFileClient{Dir: dir}
+
+	// Act
+	_, err := c.GetIncomeStatement("ACME")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrMissingLineItem)
+	assert.Contains(t, err.Error(), "Cost Of Revenue, Net Income")
+}
+
+func TestXLSXIncomeStatement(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	f := excelize.NewFile()
+	f.SetSheetName("Sheet1", "income")
+	f.SetSheetRow("income", "A1", &[]interface{}{"index", "2021-12-31", "2022-12-31"})
+	f.SetSheetRow("income", "A2", &[]interface{}{"Total Revenue", 100, 120})
+	f.SetSheetRow("income", "A3", &[]interface{}{"Cost Of Revenue", 60, 70})
+	f.SetSheetRow("income", "A4", &[]interface{}{"Net Income", 20, 25})
+	assert.NoError(t, f.SaveAs(filepath.Join(dir, "ACME.xlsx")))
+	c := &CSVFileClient{Dir: dir}
+
+	// Act
+	y, err := c.GetIncomeStatement("ACME")
+	x := NewIncomeStatement(y, &YahooStockInfo{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, x.Len())
+	assert.Equal(t, int64(50), x.Year(2022).GrossProfit().Units())
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// DCFOptions are the assumptions behind a discounted cash flow valuation
+type DCFOptions struct {
+	// Growth of free cash flow per year, nil uses the historical CAGR
+	Growth *float64
+	// DiscountRate is the return required of the investment
+	DiscountRate float64
+	// TerminalMultiple of the final year's free cash flow the business is sold for
+	TerminalMultiple float64
+	// Years of free cash flow to project
+	Years int
+}
+
+// DefaultDCFOptions discounts 10 years of growth at 10%, then sells at 15 times free cash flow
+func DefaultDCFOptions() DCFOptions {
+	return DCFOptions{DiscountRate: 0.1, TerminalMultiple: 15, Years: 10}
+}
+
+// Valuation is the intrinsic value of a business from its discounted future free cash flow
+type Valuation struct {
+	DCFOptions
+	// FreeCashFlow of the latest year, the base of the projection
+	FreeCashFlow float64
+	// Growth used, and whether it was derived from history
+	Growth            float64
+	GrowthFromHistory bool
+	// Projected free cash flow for each future year, undiscounted
+	Projected []float64
+	// PresentValue of the projected free cash flow
+	PresentValue float64
+	// TerminalValue is the present value of selling the business after the final year
+	TerminalValue          float64
+	IntrinsicValue         float64
+	SharesOutstanding      int64
+	IntrinsicValuePerShare float64
+	CurrentPrice           float64
+	// MarginOfSafety is how far the price is below intrinsic value, as a share of intrinsic value.
+	// NaN when there is no price
+	MarginOfSafety float64
+}
+
+// NewValuation values f with a discounted cash flow model
+func NewValuation(f *Fundamentals, o DCFOptions) (*Valuation, error) {
+	if f.Period == Quarterly {
+		return nil, fmt.Errorf("valuation: %w", ErrNotYearly)
+	}
+
+	latest, ok := f.CashFlow.Latest()
+
+	if !ok {
+		return nil, fmt.Errorf("valuation: no cash flow statements: %w", ErrNotEnoughData)
+	}
+
+	if o.Years <= 0 {
+		return nil, fmt.Errorf("valuation: years to project must be positive, got %d", o.Years)
+	}
+
+	if o.DiscountRate <= -1 {
+		return nil, fmt.Errorf("valuation: discount rate must be above -100%%, got %v", o.DiscountRate)
+	}
+
+	v := &Valuation{
+		DCFOptions:     o,
+		FreeCashFlow:   latest.FreeCashFlow().Float64(),
+		MarginOfSafety: math.NaN(),
+	}
+
+	if v.FreeCashFlow <= 0 {
+		return nil, fmt.Errorf("valuation: %d free cash flow is %v, nothing to discount", latest.Year, v.FreeCashFlow)
+	}
+
+	if o.Growth != nil {
+		v.Growth = *o.Growth
+	} else {
+		x := []float64{}
+		for _, y := range f.CashFlow.Values() {
+			x = append(x, y.FreeCashFlow().Float64())
+		}
+
+		v.Growth = newTrend(x, f.CashFlow.Ends(), nil).CAGR
+		v.GrowthFromHistory = true
+
+		if math.IsNaN(v.Growth) {
+			return nil, fmt.Errorf("valuation: no historical free cash flow growth, pass a growth rate: %w", ErrNotEnoughData)
+		}
+	}
+
+	fcf := v.FreeCashFlow
+
+	for year := 1; year <= o.Years; year++ {
+		fcf *= 1 + v.Growth
+		v.Projected = append(v.Projected, fcf)
+		v.PresentValue += fcf / math.Pow(1+o.DiscountRate, float64(year))
+	}
+
+	v.TerminalValue = fcf * o.TerminalMultiple / math.Pow(1+o.DiscountRate, float64(o.Years))
+	v.IntrinsicValue = v.PresentValue + v.TerminalValue
+
+	if f.Stock != nil {
+		v.SharesOutstanding = f.Stock.Root.SharesOutstanding
+		v.CurrentPrice = f.Stock.Root.CurrentPrice
+	}
+
+	if v.SharesOutstanding <= 0 {
+		return nil, fmt.Errorf("valuation: no shares outstanding: %w", ErrNotEnoughData)
+	}
+
+	v.IntrinsicValuePerShare = v.IntrinsicValue / float64(v.SharesOutstanding)
+
+	if v.CurrentPrice > 0 {
+		v.MarginOfSafety = (v.IntrinsicValuePerShare - v.CurrentPrice) / v.IntrinsicValuePerShare
+	}
+
+	return v, nil
+}
+
+// Print writes the valuation and its assumptions
+func (v *Valuation) Print(w io.Writer) {
+	source := "given"
+	if v.GrowthFromHistory {
+		source = "historical free cash flow CAGR"
+	}
+
+	fmt.Fprintf(w, "DCF growth %.2f%% (%s), discount rate %.2f%%, terminal multiple %.1fx over %d years\n",
+		v.Growth*100, source, v.DiscountRate*100, v.TerminalMultiple, v.Years)
+	fmt.Fprintf(w, "Intrinsic value %.0f (cash flows %.0f + terminal %.0f)\n", v.IntrinsicValue, v.PresentValue, v.TerminalValue)
+
+	if math.IsNaN(v.MarginOfSafety) {
+		fmt.Fprintf(w, "Intrinsic value per share %.2f, no current price\n", v.IntrinsicValuePerShare)
+		return
+	}
+
+	fmt.Fprintf(w, "Intrinsic value per share %.2f, price %.2f, margin of safety %.2f%%\n",
+		v.IntrinsicValuePerShare, v.CurrentPrice, v.MarginOfSafety*100)
+}
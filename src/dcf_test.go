@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValuationGivenGrowth(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	g := 0.0
+	o := DCFOptions{Growth: &g, DiscountRate: 0.1, TerminalMultiple: 10, Years: 1}
+
+	// Act
+	v, err := NewValuation(f, o)
+
+	// Assert
+	fcf := float64(104038000000 - 11085000000)
+	assert.NoError(t, err)
+	assert.False(t, v.GrowthFromHistory)
+	assert.InDelta(t, fcf/1.1, v.PresentValue, 1)
+	assert.InDelta(t, fcf*10/1.1, v.TerminalValue, 1)
+	assert.InDelta(t, fcf*11/1.1/16070800384, v.IntrinsicValuePerShare, 1e-6)
+	assert.InDelta(t, (v.IntrinsicValuePerShare-142.99)/v.IntrinsicValuePerShare, v.MarginOfSafety, 1e-9)
+}
+
+func TestValuationHistoricalGrowth(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+
+	// Act
+	v, err := NewValuation(f, DefaultDCFOptions())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, v.GrowthFromHistory)
+	assert.InDelta(t, 0.1318, v.Growth, 0.0001)
+	assert.Len(t, v.Projected, 10)
+}
+
+func TestValuationWithoutPrice(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	f.Stock.Root.CurrentPrice = 0
+
+	// Act
+	v, err := NewValuation(f, DefaultDCFOptions())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, math.IsNaN(v.MarginOfSafety))
+}
+
+func TestValuationWithoutShares(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	f.Stock.Root.SharesOutstanding = 0
+
+	// Act
+	_, err := NewValuation(f, DefaultDCFOptions())
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotEnoughData)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DuPont breaks return on shareholders equity into NetMargin x AssetTurnover x Leverage, so a high
+// return bought with debt can be told apart from one earned through margins or turnover
+type DuPont struct {
+	Year int
+	End  time.Time
+	// AverageTotalAssets and AverageShareholdersEquity are the mean of opening and closing balances,
+	// or the closing balance when the year before wasn't loaded
+	AverageTotalAssets        Money
+	AverageShareholdersEquity Money
+	// NetMargin is Net Earnings / Total Revenue
+	NetMargin decimal.Decimal
+	// AssetTurnover is Total Revenue / AverageTotalAssets
+	AssetTurnover decimal.Decimal
+	// Leverage is AverageTotalAssets / AverageShareholdersEquity, aka the equity multiplier
+	Leverage decimal.Decimal
+	// ReturnOnTotalAssets is Net Earnings / AverageTotalAssets
+	ReturnOnTotalAssets decimal.Decimal
+	// ReturnOnShareholdersEquity is Net Earnings / AverageShareholdersEquity
+	ReturnOnShareholdersEquity decimal.Decimal
+}
+
+// NewDuPont joins a year's income statement with its closing balance sheet and, when loaded,
+// the opening balance sheet (the year before's closing). ErrNotMeaningful when revenue, assets or equity are zero
+func NewDuPont(income *YearIncomeStatement, closing *YearBalanceSheet, opening *YearBalanceSheet) (DuPont, error) {
+	d := DuPont{
+		Year:                      income.Year,
+		End:                       income.End,
+		AverageTotalAssets:        closing.TotalAssets(),
+		AverageShareholdersEquity: closing.TotalShareholdersEquity(),
+	}
+
+	var err error
+
+	if opening != nil {
+		if d.AverageTotalAssets, err = average(opening.TotalAssets(), d.AverageTotalAssets); err != nil {
+			return d, fmt.Errorf("%d average total assets: %w", d.Year, err)
+		}
+
+		if d.AverageShareholdersEquity, err = average(opening.TotalShareholdersEquity(), d.AverageShareholdersEquity); err != nil {
+			return d, fmt.Errorf("%d average shareholders equity: %w", d.Year, err)
+		}
+	}
+
+	netEarnings := income.NetEarnings()
+	revenue := income.TotalRevenue()
+
+	ratios := []struct {
+		name     string
+		ratio    *decimal.Decimal
+		num, den Money
+	}{
+		{"net margin", &d.NetMargin, netEarnings, revenue},
+		{"asset turnover", &d.AssetTurnover, revenue, d.AverageTotalAssets},
+		{"leverage", &d.Leverage, d.AverageTotalAssets, d.AverageShareholdersEquity},
+		{"return on total assets", &d.ReturnOnTotalAssets, netEarnings, d.AverageTotalAssets},
+		{"return on shareholders equity", &d.ReturnOnShareholdersEquity, netEarnings, d.AverageShareholdersEquity},
+	}
+
+	for _, r := range ratios {
+		if *r.ratio, err = Ratio(r.num, r.den); err != nil {
+			return d, fmt.Errorf("%d %s: %w", d.Year, r.name, err)
+		}
+	}
+
+	return d, nil
+}
+
+// Annualised scales the returns and asset turnover of a period this many make up a year, e.g. 4 for a
+// quarter, to yearly rates. The margin and leverage don't depend on the length of the period
+func (d DuPont) Annualised(periodsPerYear int) DuPont {
+	n := decimal.New(int64(periodsPerYear), 0)
+
+	d.AssetTurnover = d.AssetTurnover.Mul(n)
+	d.ReturnOnTotalAssets = d.ReturnOnTotalAssets.Mul(n)
+	d.ReturnOnShareholdersEquity = d.ReturnOnShareholdersEquity.Mul(n)
+
+	return d
+}
+
+// average returns the mean of an opening and closing balance
+func average(opening, closing Money) (Money, error) {
+	sum, err := opening.Add(closing)
+
+	if err != nil {
+		return Money{}, err
+	}
+
+	return sum.Div(2)
+}
+
+// DuPonts returns the DuPont decomposition of every year with both an income statement and balance sheet,
+// skipping the years it isn't meaningful for. Quarterly returns are annualised
+func DuPonts(f *Fundamentals) []DuPont {
+	x := []DuPont{}
+
+	for _, i := range f.Income.Values() {
+		b := f.Balance.At(i.End)
+
+		if b == nil {
+			continue
+		}
+
+		d, err := NewDuPont(i, b, f.Balance.Before(i.End))
+
+		if err != nil {
+			continue
+		}
+
+		x = append(x, d.Annualised(f.PeriodsPerYear()))
+	}
+
+	return x
+}
+
+// PrintDuPonts writes the DuPont decomposition of every year as a table
+func PrintDuPonts(w io.Writer, x []DuPont) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	ends := []time.Time{}
+	for _, d := range x {
+		ends = append(ends, d.End)
+	}
+
+	label := periodLabels(ends)
+
+	fmt.Fprintln(tw, "Year\tNet Margin\tAsset Turnover\tLeverage\tReturn on Assets\tReturn on Equity")
+
+	for _, d := range x {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", label(d.End), d.NetMargin.StringFixed(4), d.AssetTurnover.StringFixed(4),
+			d.Leverage.StringFixed(4), d.ReturnOnTotalAssets.StringFixed(4), d.ReturnOnShareholdersEquity.StringFixed(4))
+	}
+
+	tw.Flush()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuPontAveragesBalances(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	i, b, o := f.Income.Year(2021), f.Balance.Year(2021), f.Balance.Year(2020)
+
+	// Act
+	d, err := NewDuPont(i, b, o)
+
+	// Assert
+	assets := (o.TotalAssets().Float64() + b.TotalAssets().Float64()) / 2
+	equity := (o.TotalShareholdersEquity().Float64() + b.TotalShareholdersEquity().Float64()) / 2
+	roe, _ := d.ReturnOnShareholdersEquity.Float64()
+	roa, _ := d.ReturnOnTotalAssets.Float64()
+	product, _ := d.NetMargin.Mul(d.AssetTurnover).Mul(d.Leverage).Float64()
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", d.AverageTotalAssets.Currency)
+	assert.InDelta(t, assets, d.AverageTotalAssets.Float64(), 1)
+	assert.InDelta(t, i.NetEarnings().Float64()/equity, roe, 1e-9)
+	assert.InDelta(t, i.NetEarnings().Float64()/assets, roa, 1e-9)
+	assert.InDelta(t, roe, product, 1e-9)
+}
+
+func TestDuPontWithoutOpeningBalance(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	i, b := f.Income.Year(2018), f.Balance.Year(2018)
+
+	// Act
+	d, err := NewDuPont(i, b, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, b.TotalAssets(), d.AverageTotalAssets)
+	assert.Equal(t, b.TotalShareholdersEquity(), d.AverageShareholdersEquity)
+}
+
+func TestDuPontWithoutEquity(t *testing.T) {
+	// Arrange
+	i := &YearIncomeStatement{Year: 2021, totalRevenue: decimal.New(100, 0), netEarnings: decimal.New(10, 0)}
+	b := &YearBalanceSheet{totalAssets: decimal.New(1000, 0)}
+
+	// Act
+	_, err := NewDuPont(i, b, nil)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotMeaningful)
+}
+
+func TestDuPonts(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+
+	// Act
+	x := DuPonts(f)
+
+	// Assert
+	assert.Len(t, x, 4)
+	assert.Equal(t, 2018, x[0].Year)
+	assert.Equal(t, "1.4744", x[3].ReturnOnShareholdersEquity.StringFixed(4))
+}
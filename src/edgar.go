@@ -0,0 +1,549 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EdgarFileClient reads SEC EDGAR XBRL "companyfacts" JSON
+// (https://data.sec.gov/api/xbrl/companyfacts/CIK##########.json) saved to disk.
+// Path is either a single companyfacts file or a directory of <SYMBOL>.json files. The filer's submissions
+// (https://data.sec.gov/submissions/CIK##########.json) may be saved alongside as <SYMBOL>.submissions.json,
+// or next to the single file with the same name and a .submissions.json extension, to say where it is based.
+type EdgarFileClient struct {
+	Path string
+}
+
+// EdgarCompanyFacts is the companyfacts document for one filer
+type EdgarCompanyFacts struct {
+	CIK        int64                              `json:"cik"`
+	EntityName string                             `json:"entityName"`
+	Facts      map[string]map[string]EdgarConcept `json:"facts"`
+}
+
+// EdgarSubmissions is the part of a filer's submissions document that says where it is based
+type EdgarSubmissions struct {
+	StateOfIncorporation string `json:"stateOfIncorporation"`
+	Addresses            struct {
+		Business struct {
+			StateOrCountry string `json:"stateOrCountry"`
+		} `json:"business"`
+	} `json:"addresses"`
+}
+
+// edgarCountries maps EDGAR state and country codes onto country names as Yahoo has them, for those with a
+// statutory tax rate. US states are in usStates
+var edgarCountries = map[string]string{
+	"A0": "Canada", "A1": "Canada", "A2": "Canada", "A3": "Canada", "A4": "Canada", "A5": "Canada", "A6": "Canada",
+	"A7": "Canada", "A8": "Canada", "A9": "Canada", "B0": "Canada", "Z4": "Canada",
+	"C3": "Australia",
+	"2M": "Germany",
+	"I0": "France",
+	"L2": "Ireland",
+	"M0": "Japan",
+	"P7": "Netherlands",
+	"V8": "Switzerland",
+	"X0": "United Kingdom",
+}
+
+// usStates are the EDGAR codes of the US states and DC
+var usStates = strings.Fields("AL AK AZ AR CA CO CT DE DC FL GA HI ID IL IN IA KS KY LA ME MD MA MI MN MS MO MT NE NV " +
+	"NH NJ NM NY NC ND OH OK OR PA RI SC SD TN TX UT VT VA WA WV WI WY")
+
+// EdgarConcept is every reported value of one taxonomy concept, grouped by unit (USD, shares, ...)
+type EdgarConcept struct {
+	Label string                 `json:"label"`
+	Units map[string][]EdgarFact `json:"units"`
+}
+
+// EdgarFact is one value of a concept as reported in one filing
+type EdgarFact struct {
+	Start string  `json:"start,omitempty"`
+	End   string  `json:"end"`
+	Value float64 `json:"val"`
+	FY    int     `json:"fy"`
+	FP    string  `json:"fp"`
+	Form  string  `json:"form"`
+	Filed string  `json:"filed"`
+}
+
+// edgarLineItem maps a Yahoo line item onto the us-gaap concepts that report it, in order of preference.
+// negate flips XBRL's sign convention (e.g. PaymentsToAcquirePropertyPlantAndEquipment is positive)
+// into Yahoo's (Capital Expenditures is negative). shares are share counts, put onto the latest filing's basis
+// across stock splits
+type edgarLineItem struct {
+	name     string
+	concepts []string
+	negate   bool
+	shares   bool
+}
+
+var edgarIncomeItems = []edgarLineItem{
+	{name: "totalRevenue", concepts: []string{"Revenues", "RevenueFromContractWithCustomerExcludingAssessedTax", "SalesRevenueNet"}},
+	{name: "costOfRevenue", concepts: []string{"CostOfRevenue", "CostOfGoodsAndServicesSold", "CostOfGoodsSold"}},
+	{name: "grossProfit", concepts: []string{"GrossProfit"}},
+	{name: "sellingGeneralAdministrative", concepts: []string{"SellingGeneralAndAdministrativeExpense"}},
+	{name: "researchDevelopment", concepts: []string{"ResearchAndDevelopmentExpense"}},
+	{name: "interestExpense", concepts: []string{"InterestExpense"}, negate: true},
+	{name: "incomeBeforeTax", concepts: []string{
+		"IncomeLossFromContinuingOperationsBeforeIncomeTaxesExtraordinaryItemsNoncontrollingInterest",
+		"IncomeLossFromContinuingOperationsBeforeIncomeTaxesMinorityInterestAndIncomeLossFromEquityMethodInvestments",
+	}},
+	{name: "incomeTaxExpense", concepts: []string{"IncomeTaxExpenseBenefit"}},
+	{name: "netIncome", concepts: []string{"NetIncomeLoss"}},
+	{name: "dilutedAverageShares", concepts: []string{"WeightedAverageNumberOfDilutedSharesOutstanding"}, shares: true},
+}
+
+var edgarBalanceItems = []edgarLineItem{
+	{name: "Cash", concepts: []string{"CashAndCashEquivalentsAtCarryingValue"}},
+	{name: "Short Term Investments", concepts: []string{"MarketableSecuritiesCurrent", "ShortTermInvestments"}},
+	{name: "Net Receivables", concepts: []string{"AccountsReceivableNetCurrent"}},
+	{name: "Inventory", concepts: []string{"InventoryNet"}},
+	{name: "Other Current Assets", concepts: []string{"OtherAssetsCurrent"}},
+	{name: "Total Current Assets", concepts: []string{"AssetsCurrent"}},
+	{name: "Long Term Investments", concepts: []string{"MarketableSecuritiesNoncurrent", "LongTermInvestments"}},
+	{name: "Property Plant Equipment", concepts: []string{"PropertyPlantAndEquipmentNet"}},
+	{name: "Good Will", concepts: []string{"Goodwill"}},
+	{name: "Intangible Assets", concepts: []string{"IntangibleAssetsNetExcludingGoodwill", "FiniteLivedIntangibleAssetsNet"}},
+	{name: "Other Assets", concepts: []string{"OtherAssetsNoncurrent"}},
+	{name: "Total Assets", concepts: []string{"Assets"}},
+	{name: "Accounts Payable", concepts: []string{"AccountsPayableCurrent"}},
+	{name: "Short Long Term Debt", concepts: []string{"LongTermDebtCurrent", "DebtCurrent"}},
+	{name: "Other Current Liab", concepts: []string{"OtherLiabilitiesCurrent"}},
+	{name: "Total Current Liabilities", concepts: []string{"LiabilitiesCurrent"}},
+	{name: "Long Term Debt", concepts: []string{"LongTermDebtNoncurrent", "LongTermDebt"}},
+	{name: "Deferred Long Term Liab", concepts: []string{"DeferredIncomeTaxLiabilitiesNet", "DeferredTaxLiabilitiesNoncurrent"}},
+	{name: "Minority Interest", concepts: []string{"MinorityInterest"}},
+	{name: "Other Liab", concepts: []string{"OtherLiabilitiesNoncurrent"}},
+	{name: "Total Liab", concepts: []string{"Liabilities"}},
+	{name: "Preferred Stock", concepts: []string{"PreferredStockValue"}},
+	{name: "Common Stock", concepts: []string{"CommonStocksIncludingAdditionalPaidInCapital", "CommonStockValue"}},
+	{name: "Retained Earnings", concepts: []string{"RetainedEarningsAccumulatedDeficit"}},
+	{name: "Treasury Stock", concepts: []string{"TreasuryStockValue"}, negate: true},
+	{name: "Total Stockholder Equity", concepts: []string{"StockholdersEquity"}},
+}
+
+var edgarCashFlowItems = []edgarLineItem{
+	{name: "Net Income", concepts: []string{"NetIncomeLoss"}},
+	{name: "Depreciation", concepts: []string{"DepreciationDepletionAndAmortization", "DepreciationAmortizationAndAccretionNet", "Depreciation"}},
+	{name: "Change To Account Receivables", concepts: []string{"IncreaseDecreaseInAccountsReceivable"}, negate: true},
+	{name: "Change To Inventory", concepts: []string{"IncreaseDecreaseInInventories"}, negate: true},
+	{name: "Change To Liabilities", concepts: []string{"IncreaseDecreaseInAccountsPayable"}},
+	{name: "Total Cash From Operating Activities", concepts: []string{"NetCashProvidedByUsedInOperatingActivities"}},
+	{name: "Capital Expenditures", concepts: []string{"PaymentsToAcquirePropertyPlantAndEquipment"}, negate: true},
+	{name: "Total Cashflows From Investing Activities", concepts: []string{"NetCashProvidedByUsedInInvestingActivities"}},
+	{name: "Dividends Paid", concepts: []string{"PaymentsOfDividends", "PaymentsOfDividendsCommonStock"}, negate: true},
+	{name: "Issuance Of Stock", concepts: []string{"ProceedsFromIssuanceOfCommonStock"}},
+	{name: "Repurchase Of Stock", concepts: []string{"PaymentsForRepurchaseOfCommonStock"}, negate: true},
+	{name: "Total Cash From Financing Activities", concepts: []string{"NetCashProvidedByUsedInFinancingActivities"}},
+	{name: "Change In Cash", concepts: []string{
+		"CashCashEquivalentsRestrictedCashAndRestrictedCashEquivalentsPeriodIncreaseDecreaseIncludingExchangeRateEffect",
+		"CashAndCashEquivalentsPeriodIncreaseDecrease",
+	}},
+}
+
+// path returns the file of symbol with the given extension, e.g. ".json"
+func (e *EdgarFileClient) path(symbol, ext string) (string, error) {
+	info, err := os.Stat(e.Path)
+
+	if err != nil {
+		return "", err
+	}
+
+	if info.IsDir() {
+		return filepath.Join(e.Path, strings.ToUpper(symbol)+ext), nil
+	}
+
+	return strings.TrimSuffix(e.Path, ".json") + ext, nil
+}
+
+// GetCompanyFacts reads the companyfacts document for symbol
+func (e *EdgarFileClient) GetCompanyFacts(symbol string) (*EdgarCompanyFacts, error) {
+	path, err := e.path(symbol, ".json")
+
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var x *EdgarCompanyFacts
+	err = json.Unmarshal(b, &x)
+
+	if err != nil {
+		return nil, fmt.Errorf("edgar: %s: %w", path, err)
+	}
+
+	return x, nil
+}
+
+func (e *EdgarFileClient) GetIncomeStatement(symbol string) (*YahooIncomeStatementV15, error) {
+	cf, err := e.GetCompanyFacts(symbol)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ends := cf.FiscalYearEnds()
+	rows := cf.annualRows(edgarIncomeItems, ends)
+
+	y := &YahooIncomeStatementV15{}
+
+	for _, end := range ends {
+		item := func(name string) YahooIncomeStatementItem {
+			v := rows[name][end]
+			return YahooIncomeStatementItem{Raw: v, Fmt: fmt.Sprint(v)}
+		}
+
+		y.Root.IncomeStatementHistory = append(y.Root.IncomeStatementHistory, YahooIncomeStatementHistory{
+			EndDate:                      YahooIncomeStatementItem{Raw: end.Unix(), Fmt: end.Format("2006-01-02")},
+			TotalRevenue:                 item("totalRevenue"),
+			CostOfRevenue:                item("costOfRevenue"),
+			GrossProfit:                  item("grossProfit"),
+			SellingGeneralAdministrative: item("sellingGeneralAdministrative"),
+			ResearchDevelopment:          item("researchDevelopment"),
+			InterestExpense:              item("interestExpense"),
+			IncomeBeforeTax:              item("incomeBeforeTax"),
+			IncomeTaxExpense:             item("incomeTaxExpense"),
+			NetEarnings:                  item("netIncome"),
+			DilutedAverageShares:         item("dilutedAverageShares"),
+		})
+	}
+
+	return y, nil
+}
+
+func (e *EdgarFileClient) GetBalanceSheet(symbol string) (*YahooBalanceSheetV1, error) {
+	cf, err := e.GetCompanyFacts(symbol)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows := cf.annualRows(edgarBalanceItems, cf.FiscalYearEnds())
+
+	x := &YahooBalanceSheetV1{}
+
+	for _, item := range edgarBalanceItems {
+		if len(rows[item.name]) > 0 {
+			x.Root = append(x.Root, YahooBalanceItem{Name: item.name, Values: rows[item.name]})
+		}
+	}
+
+	return x, nil
+}
+
+func (e *EdgarFileClient) GetCashFlow(symbol string) (*YahooCashFlowV1, error) {
+	cf, err := e.GetCompanyFacts(symbol)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows := cf.annualRows(edgarCashFlowItems, cf.FiscalYearEnds())
+
+	x := &YahooCashFlowV1{}
+
+	for _, item := range edgarCashFlowItems {
+		if len(rows[item.name]) > 0 {
+			x.Root = append(x.Root, YahooCashItem{Name: item.name, Values: rows[item.name]})
+		}
+	}
+
+	return x, nil
+}
+
+// GetStockInfo returns the most recently reported shares outstanding, EDGAR has no market data
+func (e *EdgarFileClient) GetStockInfo(symbol string) (*YahooStockInfo, error) {
+	cf, err := e.GetCompanyFacts(symbol)
+
+	if err != nil {
+		return nil, err
+	}
+
+	x := &YahooStockInfo{}
+
+	var latest string
+	for _, f := range cf.Facts["dei"]["EntityCommonStockSharesOutstanding"].Units["shares"] {
+		if f.End >= latest {
+			latest = f.End
+			x.Root.SharesOutstanding = int64(f.Value)
+		}
+	}
+
+	x.Root.FinancialCurrency = cf.Currency()
+
+	s, err := e.GetSubmissions(symbol)
+
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if s != nil {
+		x.Root.Country = s.Country()
+	}
+
+	return x, nil
+}
+
+// GetSubmissions reads the submissions document for symbol, an error satisfying os.IsNotExist when there is none
+func (e *EdgarFileClient) GetSubmissions(symbol string) (*EdgarSubmissions, error) {
+	path, err := e.path(symbol, ".submissions.json")
+
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var x *EdgarSubmissions
+
+	if err := json.Unmarshal(b, &x); err != nil {
+		return nil, fmt.Errorf("edgar: %s: %w", path, err)
+	}
+
+	return x, nil
+}
+
+// Country returns the country of the filer's business address, else of its incorporation, named as Yahoo
+// names it. Empty when neither is known
+func (s *EdgarSubmissions) Country() string {
+	for _, code := range []string{s.Addresses.Business.StateOrCountry, s.StateOfIncorporation} {
+		code = strings.ToUpper(strings.TrimSpace(code))
+
+		for _, state := range usStates {
+			if code == state {
+				return "United States"
+			}
+		}
+
+		if c, ok := edgarCountries[code]; ok {
+			return c
+		}
+	}
+
+	return ""
+}
+
+// FiscalYearEnds returns the end of every fiscal year with annual net income reported on a 10-K, oldest first
+func (cf *EdgarCompanyFacts) FiscalYearEnds() []time.Time {
+	seen := map[time.Time]bool{}
+
+	for _, f := range cf.facts("NetIncomeLoss") {
+		if end, ok := f.annual(); ok {
+			seen[end] = true
+		}
+	}
+
+	ends := []time.Time{}
+	for end := range seen {
+		ends = append(ends, end)
+	}
+
+	sort.Slice(ends, func(i, j int) bool { return ends[i].Before(ends[j]) })
+
+	return ends
+}
+
+// annualRows returns the value of every line item for each fiscal year end, keyed by line item name
+func (cf *EdgarCompanyFacts) annualRows(items []edgarLineItem, ends []time.Time) map[string]map[time.Time]int64 {
+	want := map[time.Time]bool{}
+	for _, end := range ends {
+		want[end] = true
+	}
+
+	rows := map[string]map[time.Time]int64{}
+
+	for _, item := range items {
+		values := map[time.Time]int64{}
+
+		for _, concept := range item.concepts {
+			latest := map[time.Time]EdgarFact{}
+
+			for _, f := range cf.facts(concept) {
+				end, ok := f.annual()
+
+				if !ok || !want[end] {
+					continue
+				}
+
+				// An earlier concept in the list already reported this year
+				if _, ok := values[end]; ok {
+					continue
+				}
+
+				// Later filings carry restated comparatives, prefer them
+				if prev, ok := latest[end]; ok && prev.Filed > f.Filed {
+					continue
+				}
+
+				latest[end] = f
+			}
+
+			split := map[string]float64{}
+			if item.shares {
+				split = splitFactors(cf.facts(concept))
+			}
+
+			for end, f := range latest {
+				v := int64(f.Value)
+
+				// Years only reported before a split are counted in the shares of the time
+				if r, ok := split[f.Filed]; ok {
+					v = int64(math.Round(f.Value * r))
+				}
+
+				if item.negate {
+					v = -v
+				}
+
+				values[end] = v
+			}
+		}
+
+		rows[item.name] = values
+	}
+
+	return rows
+}
+
+// splitFactors returns the factor that puts the share counts of every annual report onto the basis of the
+// latest one. A year a later report restated for a split shows it as a whole ratio between the two counts,
+// and the factors chain back through the reports that overlap
+func splitFactors(facts []EdgarFact) map[string]float64 {
+	filings := map[string]map[time.Time]float64{}
+
+	for _, f := range facts {
+		if end, ok := f.annual(); ok {
+			if filings[f.Filed] == nil {
+				filings[f.Filed] = map[time.Time]float64{}
+			}
+
+			filings[f.Filed][end] = f.Value
+		}
+	}
+
+	filed := []string{}
+	for k := range filings {
+		filed = append(filed, k)
+	}
+
+	// Latest first
+	sort.Sort(sort.Reverse(sort.StringSlice(filed)))
+
+	factors := map[string]float64{}
+
+	for i, filing := range filed {
+		factors[filing] = 1
+
+		// The closest later report of one of the same years
+		for j := i - 1; j >= 0; j-- {
+			if r, ok := splitRatio(filings[filing], filings[filed[j]]); ok {
+				factors[filing] = factors[filed[j]] * r
+				break
+			}
+		}
+	}
+
+	return factors
+}
+
+// splitRatio returns the split between the counts two reports give a year they both cover, 1 when the counts
+// differ by less than a whole ratio either way
+func splitRatio(earlier, later map[time.Time]float64) (float64, bool) {
+	for end, was := range earlier {
+		now, ok := later[end]
+
+		if !ok || was <= 0 || now <= 0 {
+			continue
+		}
+
+		if n := math.Round(now / was); n >= 2 && math.Abs(now/was/n-1) < 0.01 {
+			return n, true
+		}
+
+		if n := math.Round(was / now); n >= 2 && math.Abs(was/now/n-1) < 0.01 {
+			return 1 / n, true
+		}
+
+		return 1, true
+	}
+
+	return 0, false
+}
+
+// Currency returns the unit net income is reported in, USD unless a foreign filer reports in its own currency
+func (cf *EdgarCompanyFacts) Currency() string {
+	units := cf.Facts["us-gaap"]["NetIncomeLoss"].Units
+
+	if _, ok := units["USD"]; ok {
+		return "USD"
+	}
+
+	for unit := range units {
+		return unit
+	}
+
+	return ""
+}
+
+// facts returns every annual report value of a us-gaap concept in the reporting currency
+func (cf *EdgarCompanyFacts) facts(concept string) []EdgarFact {
+	units := cf.Facts["us-gaap"][concept].Units
+
+	if x, ok := units["USD"]; ok {
+		return x
+	}
+
+	// Foreign filers report in their own currency
+	for _, x := range units {
+		return x
+	}
+
+	return nil
+}
+
+// annual reports whether the fact comes from an annual report and covers a full fiscal year,
+// returning the fiscal year end
+func (f EdgarFact) annual() (time.Time, bool) {
+	if f.FP != "FY" || !(strings.HasPrefix(f.Form, "10-K") || strings.HasPrefix(f.Form, "20-F")) {
+		return time.Time{}, false
+	}
+
+	end, err := time.Parse("2006-01-02", f.End)
+
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	// Balance sheet values are a single instant
+	if f.Start == "" {
+		return end, true
+	}
+
+	start, err := time.Parse("2006-01-02", f.Start)
+
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	// 52/53 week fiscal years, excluding quarters reported inside the 10-K
+	days := end.Sub(start).Hours() / 24
+
+	return end, days > 350 && days < 380
+}
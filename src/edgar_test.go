@@ -0,0 +1,190 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEdgarIncomeStatement(t *testing.T) {
+	// Arrange
+	e := &EdgarFileClient{Path: "./json/edgar"}
+	y, err := e.GetIncomeStatement("aapl")
+	s, _ := e.GetStockInfo("aapl")
+
+	// Act
+	x := NewIncomeStatement(y, s)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 5, x.Len())
+	assert.Equal(t, int64(229234000000), x.Year(2017).TotalRevenue().Units())
+	assert.Equal(t, int64(141048000000), x.Year(2017).CostOfRevenue().Units())
+	assert.Equal(t, int64(94680000000), x.Year(2021).NetEarnings().Units())
+	assert.Equal(t, int64(16864919000), x.Year(2021).SharesOutstanding())
+}
+
+func TestEdgarDilutedSharesPerYear(t *testing.T) {
+	// Arrange
+	e := &EdgarFileClient{Path: "./json/edgar"}
+	y, _ := e.GetIncomeStatement("AAPL")
+	s, _ := e.GetStockInfo("AAPL")
+
+	// Act
+	x := NewIncomeStatement(y, s)
+
+	// Assert
+	assert.Equal(t, int64(16406397000), s.Root.SharesOutstanding)
+	// The 2020 10-K restated 2018 for the 4-for-1 split
+	assert.Equal(t, int64(20000436000), x.Year(2018).SharesOutstanding())
+	eps, err := x.Year(2021).PerShareEarnings()
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", eps.Currency)
+	assert.InDelta(t, 94680000000.0/16864919000, eps.Float64(), 1e-9)
+}
+
+func TestEdgarSplitAdjustedShares(t *testing.T) {
+	// Arrange
+	f, err := LoadFundamentals(&EdgarFileClient{Path: "./json/edgar"}, "AAPL")
+
+	// Act
+	tr := f.Income.PerShareEarningsTrend(nil)
+
+	// Assert
+	assert.NoError(t, err)
+	// 2017 was only reported before the 4-for-1 split of 2020, the 2018 10-K's 5251692000 shares are 4 times that today
+	assert.Equal(t, int64(4*5251692000), f.Income.Year(2017).SharesOutstanding())
+	assert.Equal(t, int64(20000436000), f.Income.Year(2018).SharesOutstanding())
+	eps2017, _ := f.Income.Year(2017).PerShareEarnings()
+	eps2018, _ := f.Income.Year(2018).PerShareEarnings()
+	assert.Less(t, eps2017.Float64(), eps2018.Float64())
+	assert.Greater(t, tr.CAGR, 0.0)
+	assert.NotEqual(t, BAD, tr.Rating)
+}
+
+func TestSplitFactors(t *testing.T) {
+	// Arrange
+	facts := []EdgarFact{
+		{Start: "2016-10-01", End: "2017-09-30", Value: 100, FP: "FY", Form: "10-K", Filed: "2017-11-01"},
+		{Start: "2016-10-01", End: "2017-09-30", Value: 100, FP: "FY", Form: "10-K", Filed: "2018-11-01"},
+		{Start: "2017-10-01", End: "2018-09-30", Value: 98, FP: "FY", Form: "10-K", Filed: "2018-11-01"},
+		{Start: "2017-10-01", End: "2018-09-30", Value: 196, FP: "FY", Form: "10-K", Filed: "2019-11-01"},
+		{Start: "2018-10-01", End: "2019-09-30", Value: 190, FP: "FY", Form: "10-K", Filed: "2019-11-01"},
+		{Start: "2018-10-01", End: "2019-09-30", Value: 19, FP: "FY", Form: "10-K", Filed: "2020-11-01"},
+	}
+
+	// Act
+	x := splitFactors(facts)
+
+	// Assert
+	assert.Equal(t, map[string]float64{"2017-11-01": 0.2, "2018-11-01": 0.2, "2019-11-01": 0.1, "2020-11-01": 1}, x)
+}
+
+func TestEdgarPrefersRestatedValues(t *testing.T) {
+	// Arrange
+	e := &EdgarFileClient{Path: "./json/edgar/AAPL.json"}
+	y, _ := e.GetIncomeStatement("AAPL")
+
+	// Act
+	x := NewIncomeStatement(y, &YahooStockInfo{})
+
+	// Assert
+	assert.Equal(t, int64(59500000000), x.Year(2018).NetEarnings().Units())
+}
+
+func TestEdgarBalanceSheet(t *testing.T) {
+	// Arrange
+	e := &EdgarFileClient{Path: "./json/edgar"}
+	ybs, err := e.GetBalanceSheet("AAPL")
+
+	// Act
+	bs := NewBalanceSheet(ybs)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 5, bs.Len())
+	assert.Equal(t, int64(365725000000), bs.Year(2018).TotalAssets().Units())
+	assert.Equal(t, int64(8784000000), bs.Year(2018).ShortTermDebt().Units())
+	assert.Equal(t, int64(109106000000), bs.Year(2021).LongTermDebt().Units())
+}
+
+func TestEdgarCashFlow(t *testing.T) {
+	// Arrange
+	e := &EdgarFileClient{Path: "./json/edgar"}
+	ycf, err := e.GetCashFlow("AAPL")
+
+	// Act
+	cf := NewCashFlowStatement(ycf)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 5, cf.Len())
+	assert.Equal(t, int64(-11085000000), cf.Year(2021).CapitalExpenditures().Units())
+	assert.Equal(t, int64(104038000000-11085000000), cf.Year(2021).FreeCashFlow().Units())
+}
+
+func TestEdgarMissingSymbol(t *testing.T) {
+	// Arrange
+	e := &EdgarFileClient{Path: "./json/edgar"}
+
+	// Act
+	_, err := e.GetIncomeStatement("MSFT")
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestEdgarCountry(t *testing.T) {
+	// Arrange
+	dir := &EdgarFileClient{Path: "./json/edgar"}
+	file := &EdgarFileClient{Path: "./json/edgar/AAPL.json"}
+	missing := &EdgarFileClient{Path: t.TempDir()}
+	os.WriteFile(filepath.Join(missing.Path, "AAPL.json"), []byte(`{"cik": 320193, "facts": {}}`), 0o644)
+
+	// Act
+	fromDir, dirErr := dir.GetStockInfo("aapl")
+	fromFile, fileErr := file.GetStockInfo("AAPL")
+	none, noneErr := missing.GetStockInfo("AAPL")
+
+	// Assert
+	assert.NoError(t, dirErr)
+	assert.NoError(t, fileErr)
+	assert.NoError(t, noneErr)
+	assert.Equal(t, "United States", fromDir.Root.Country)
+	assert.Equal(t, "United States", fromFile.Root.Country)
+	assert.Empty(t, none.Root.Country)
+}
+
+func TestEdgarSubmissionsCountry(t *testing.T) {
+	tests := map[string]EdgarSubmissions{
+		"Ireland":       {StateOfIncorporation: "L2"},
+		"United States": {StateOfIncorporation: "DE"},
+		"":              {StateOfIncorporation: "ZZ"},
+	}
+
+	for country, s := range tests {
+		// Assert
+		assert.Equal(t, country, s.Country())
+	}
+
+	// Arrange
+	s := EdgarSubmissions{StateOfIncorporation: "DE"}
+	s.Addresses.Business.StateOrCountry = "X0"
+
+	// Assert
+	assert.Equal(t, "United Kingdom", s.Country(), "the business address wins")
+}
+
+func TestEdgarTaxCheck(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&EdgarFileClient{Path: "./json/edgar"}, "AAPL")
+
+	// Act
+	c, err := NewLegitimacyRating(f, nil).IncomeTaxExpenseAt(f.Income.Year(2021).End)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0.21, c.StatutoryRate)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+)
+
+// EquityBondOptions are the assumptions behind treating a share as a bond
+type EquityBondOptions struct {
+	// BondYield of the government bond the equity is compared against, e.g. 0.04
+	BondYield float64
+	// Years to project per share earnings
+	Years int
+}
+
+// DefaultEquityBondOptions compares against a 4% government bond over 10 years
+func DefaultEquityBondOptions() EquityBondOptions {
+	return EquityBondOptions{BondYield: 0.04, Years: 10}
+}
+
+// EquityBond treats per share earnings as the coupon of a bond bought at the current price,
+// a coupon that increases as earnings grow
+type EquityBond struct {
+	EquityBondOptions
+	// Year of the latest per share earnings
+	Year             int
+	PerShareEarnings float64
+	Price            float64
+	// InitialYield is PerShareEarnings / Price
+	InitialYield float64
+	// Growth is the historical CAGR of per share earnings
+	Growth    float64
+	Projected []ProjectedYield
+	// Overtakes is true when the equity yield beats the bond within the projection,
+	// in fiscal year OvertakesIn
+	Overtakes   bool
+	OvertakesIn int
+}
+
+// ProjectedYield is the equity's yield on the price paid today, in a future fiscal year
+type ProjectedYield struct {
+	Year             int
+	PerShareEarnings float64
+	Yield            float64
+}
+
+// NewEquityBond projects the earnings yield of f at its current price
+func NewEquityBond(f *Fundamentals, o EquityBondOptions) (*EquityBond, error) {
+	if f.Period == Quarterly {
+		return nil, fmt.Errorf("equity bond: %w", ErrNotYearly)
+	}
+
+	latest, ok := f.Income.Latest()
+
+	if !ok {
+		return nil, fmt.Errorf("equity bond: no income statements: %w", ErrNotEnoughData)
+	}
+
+	if f.Stock == nil || f.Stock.Root.CurrentPrice <= 0 {
+		return nil, fmt.Errorf("equity bond: no current price: %w", ErrNotEnoughData)
+	}
+
+	latestEPS, err := latest.PerShareEarnings()
+
+	if err != nil {
+		return nil, fmt.Errorf("equity bond: %d per share earnings: %w", latest.Year, err)
+	}
+
+	e := &EquityBond{
+		EquityBondOptions: o,
+		Year:              latest.Year,
+		PerShareEarnings:  latestEPS.Float64(),
+		Price:             f.Stock.Root.CurrentPrice,
+		Growth:            f.Income.PerShareEarningsTrend(nil).CAGR,
+	}
+
+	if math.IsNaN(e.Growth) {
+		return nil, fmt.Errorf("equity bond: no historical per share earnings growth: %w", ErrNotEnoughData)
+	}
+
+	e.InitialYield = e.PerShareEarnings / e.Price
+
+	if e.InitialYield > o.BondYield {
+		e.Overtakes = true
+		e.OvertakesIn = e.Year
+	}
+
+	eps := e.PerShareEarnings
+
+	for i := 1; i <= o.Years; i++ {
+		eps *= 1 + e.Growth
+
+		p := ProjectedYield{Year: e.Year + i, PerShareEarnings: eps, Yield: eps / e.Price}
+		e.Projected = append(e.Projected, p)
+
+		if !e.Overtakes && p.Yield > o.BondYield {
+			e.Overtakes = true
+			e.OvertakesIn = p.Year
+		}
+	}
+
+	return e, nil
+}
+
+// Print writes the projected yield against the bond
+func (e *EquityBond) Print(w io.Writer) {
+	fmt.Fprintf(w, "Equity bond %d per share earnings %.2f at price %.2f, initial yield %.2f%%, growing %.2f%% a year\n",
+		e.Year, e.PerShareEarnings, e.Price, e.InitialYield*100, e.Growth*100)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Year\tPer Share Earnings\tEquity Yield\tBond Yield")
+
+	for _, p := range e.Projected {
+		fmt.Fprintf(tw, "%d\t%.2f\t%.2f%%\t%.2f%%\n", p.Year, p.PerShareEarnings, p.Yield*100, e.BondYield*100)
+	}
+
+	tw.Flush()
+
+	switch {
+	case e.Overtakes && e.OvertakesIn == e.Year:
+		fmt.Fprintf(w, "Equity yield already beats the %.2f%% bond\n", e.BondYield*100)
+	case e.Overtakes:
+		fmt.Fprintf(w, "Equity yield overtakes the %.2f%% bond in %d\n", e.BondYield*100, e.OvertakesIn)
+	default:
+		fmt.Fprintf(w, "Equity yield does not overtake the %.2f%% bond within %d years\n", e.BondYield*100, e.Years)
+	}
+}
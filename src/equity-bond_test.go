@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEquityBondAlreadyBeatsBond(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+
+	// Act
+	e, err := NewEquityBond(f, DefaultEquityBondOptions())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2021, e.Year)
+	assert.InDelta(t, 94680000000.0/16070800384/142.99, e.InitialYield, 1e-9)
+	assert.True(t, e.Overtakes)
+	assert.Equal(t, 2021, e.OvertakesIn)
+	assert.Len(t, e.Projected, 10)
+}
+
+func TestEquityBondOvertakesBond(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+
+	// Act
+	e, err := NewEquityBond(f, EquityBondOptions{BondYield: 0.06, Years: 10})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, e.Overtakes)
+	assert.Equal(t, 2024, e.OvertakesIn)
+	assert.Greater(t, e.Projected[2].Yield, 0.06)
+	assert.Less(t, e.Projected[1].Yield, 0.06)
+}
+
+func TestEquityBondNeverOvertakes(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+
+	// Act
+	e, err := NewEquityBond(f, EquityBondOptions{BondYield: 0.5, Years: 5})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, e.Overtakes)
+}
+
+func TestEquityBondWithoutPrice(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	f.Stock.Root.CurrentPrice = 0
+
+	// Act
+	_, err := NewEquityBond(f, DefaultEquityBondOptions())
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotEnoughData)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// RecordingProvider passes every call through to Provider, saving each response under Dir as
+// <SYMBOL>/<endpoint>.json for a ReplayClient to serve later
+type RecordingProvider struct {
+	Provider FundamentalsProvider
+	Dir      string
+}
+
+// ReplayClient serves the responses a RecordingProvider saved in Dir, by symbol, without network access
+type ReplayClient struct {
+	Dir string
+}
+
+func (r *RecordingProvider) GetIncomeStatement(symbol string) (*YahooIncomeStatementV15, error) {
+	return recorded(r, symbol, "income", r.Provider.GetIncomeStatement)
+}
+
+func (r *RecordingProvider) GetBalanceSheet(symbol string) (*YahooBalanceSheetV1, error) {
+	return recorded(r, symbol, "balance", r.Provider.GetBalanceSheet)
+}
+
+func (r *RecordingProvider) GetCashFlow(symbol string) (*YahooCashFlowV1, error) {
+	return recorded(r, symbol, "cash-flow", r.Provider.GetCashFlow)
+}
+
+func (r *RecordingProvider) GetStockInfo(symbol string) (*YahooStockInfo, error) {
+	return recorded(r, symbol, "stock", r.Provider.GetStockInfo)
+}
+
+func (r *RecordingProvider) GetQuarterlyBalanceSheet(symbol string) (*YahooBalanceSheetV1, error) {
+	q, ok := r.Provider.(QuarterlyProvider)
+
+	if !ok {
+		return nil, fmt.Errorf("provider %T has no quarterly statements", r.Provider)
+	}
+
+	return recorded(r, symbol, "quarterly-balance", q.GetQuarterlyBalanceSheet)
+}
+
+func (r *RecordingProvider) GetQuarterlyCashFlow(symbol string) (*YahooCashFlowV1, error) {
+	q, ok := r.Provider.(QuarterlyProvider)
+
+	if !ok {
+		return nil, fmt.Errorf("provider %T has no quarterly statements", r.Provider)
+	}
+
+	return recorded(r, symbol, "quarterly-cash-flow", q.GetQuarterlyCashFlow)
+}
+
+// recorded fetches endpoint and saves the response, failing when it can't be saved so a recording is never partial.
+// A provider with raw responses has them saved as sent, any other has its statements saved in Yahoo's layout
+func recorded[T any](r *RecordingProvider, symbol, endpoint string, fetch func(string) (*T, error)) (*T, error) {
+	s, err := checkSymbol(symbol)
+
+	if err != nil {
+		return nil, fmt.Errorf("record: %w", err)
+	}
+
+	var x *T
+	var b []byte
+
+	if raw, ok := r.Provider.(RawProvider); ok {
+		if b, err = raw.GetRawResponse(symbol, endpoint); err != nil {
+			return nil, err
+		}
+
+		if x, err = decodeResponse[T](b, endpoint); err != nil {
+			return nil, err
+		}
+	} else {
+		if x, err = fetch(symbol); err != nil {
+			return nil, err
+		}
+
+		if b, err = json.Marshal(x); err != nil {
+			return nil, fmt.Errorf("record: %w", err)
+		}
+	}
+
+	if err := writeResponseFile(filepath.Join(r.Dir, s, endpoint+".json"), b, time.Now()); err != nil {
+		return nil, fmt.Errorf("record: %w", err)
+	}
+
+	return x, nil
+}
+
+func (r *ReplayClient) GetIncomeStatement(symbol string) (*YahooIncomeStatementV15, error) {
+	f, err := r.files(symbol)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f.GetIncomeStatement(symbol)
+}
+
+func (r *ReplayClient) GetBalanceSheet(symbol string) (*YahooBalanceSheetV1, error) {
+	f, err := r.files(symbol)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f.GetBalanceSheet(symbol)
+}
+
+func (r *ReplayClient) GetCashFlow(symbol string) (*YahooCashFlowV1, error) {
+	f, err := r.files(symbol)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f.GetCashFlow(symbol)
+}
+
+func (r *ReplayClient) GetStockInfo(symbol string) (*YahooStockInfo, error) {
+	f, err := r.files(symbol)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f.GetStockInfo(symbol)
+}
+
+func (r *ReplayClient) GetQuarterlyBalanceSheet(symbol string) (*YahooBalanceSheetV1, error) {
+	f, err := r.files(symbol)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f.GetQuarterlyBalanceSheet(symbol)
+}
+
+func (r *ReplayClient) GetQuarterlyCashFlow(symbol string) (*YahooCashFlowV1, error) {
+	f, err := r.files(symbol)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f.GetQuarterlyCashFlow(symbol)
+}
+
+// GetRawResponse returns the recorded body of the endpoint response for symbol
+func (r *ReplayClient) GetRawResponse(symbol, endpoint string) ([]byte, error) {
+	f, err := r.files(symbol)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f.GetRawResponse(symbol, endpoint)
+}
+
+// files returns the recorded responses of symbol, ErrNotFound when it was never recorded
+func (r *ReplayClient) files(symbol string) (*YahooFileClient, error) {
+	s, err := checkSymbol(symbol)
+
+	if err != nil {
+		return nil, fmt.Errorf("replay: %q is not a ticker: %w", symbol, ErrNotFound)
+	}
+
+	dir := filepath.Join(r.Dir, s)
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("replay: no recording of %q in %s: %w", symbol, r.Dir, ErrNotFound)
+	}
+
+	return &YahooFileClient{Dir: dir}, nil
+}
+
+// Symbols returns every recorded symbol, in order
+func (r *ReplayClient) Symbols() ([]string, error) {
+	entries, err := os.ReadDir(r.Dir)
+
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+
+	x := []string{}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			x = append(x, e.Name())
+		}
+	}
+
+	sort.Strings(x)
+
+	return x, nil
+}
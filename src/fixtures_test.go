@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordingProviderReplays(t *testing.T) {
+	// Arrange
+	y, _ := yahooTestServer(t)
+	dir := t.TempDir()
+	r := &RecordingProvider{Provider: y, Dir: dir}
+	live, err := LoadFundamentalsFor(r, "AAPL", TTM)
+	assert.NoError(t, err)
+
+	// Act
+	replayed, err := LoadFundamentalsFor(&ReplayClient{Dir: dir}, "aapl", TTM)
+	symbols, _ := (&ReplayClient{Dir: dir}).Symbols()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"AAPL"}, symbols)
+	assert.Equal(t, ScoreValue(live, nil).Total, ScoreValue(replayed, nil).Total)
+}
+
+func TestRecordingProviderKeepsRawResponses(t *testing.T) {
+	// Arrange
+	y, _ := yahooTestServer(t)
+	dir := t.TempDir()
+	sent, _ := os.ReadFile("./json/fixtures/yahoo-rapidapi/AAPL/stock.json")
+
+	// Act
+	_, err := (&RecordingProvider{Provider: y, Dir: dir}).GetStockInfo("aapl")
+	b, _ := os.ReadFile(filepath.Join(dir, "AAPL", "stock.json"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, sent, b, "fields the stock info doesn't model, such as address1, are kept")
+}
+
+func TestReplayClientUnknownSymbol(t *testing.T) {
+	// Arrange
+	r := &ReplayClient{Dir: "./json/fixtures/yahoo-rapidapi"}
+
+	// Act
+	_, err := r.GetIncomeStatement("NOPE")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMockClientUnknownSymbol(t *testing.T) {
+	// Arrange
+	m := &YahooMockClient{}
+
+	// Act
+	_, err := LoadFundamentals(m, "NOPE")
+	_, pathErr := m.GetStockInfo("../AAPL")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, pathErr, ErrNotFound)
+}
+
+func TestReplayFixtures(t *testing.T) {
+	r := &ReplayClient{Dir: "./json/fixtures/yahoo-rapidapi"}
+	symbols, err := r.Symbols()
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, symbols)
+
+	for _, symbol := range symbols {
+		t.Run(symbol, func(t *testing.T) {
+			// Act
+			f, err := LoadFundamentals(r, symbol)
+
+			// Assert
+			if assert.NoError(t, err) {
+				assert.NotEmpty(t, ScoreValue(f, nil).Explanations)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrUnauthorized is a rejected API key (401), or one not subscribed to the API (403)
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrQuotaExceeded is a rate limit or plan quota that still rejects requests (429) after every retry
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrNotFound is an unknown symbol or endpoint (404)
+	ErrNotFound = errors.New("not found")
+	// ErrUpstream is a server error (5xx) that outlasted every retry
+	ErrUpstream = errors.New("upstream error")
+	// ErrMalformedPayload is a response that isn't the JSON expected
+	ErrMalformedPayload = errors.New("malformed payload")
+)
+
+// HTTPError is a response with an unsuccessful status code, it unwraps to the matching Err sentinel
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	// Body is the start of the response, which usually says why
+	Body string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// Unwrap returns the sentinel error of the status code
+func (e *HTTPError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrQuotaExceeded
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode >= 500:
+		return ErrUpstream
+	}
+
+	return nil
+}
+
+// retryable reports whether the request may succeed if sent again
+func (e *HTTPError) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// RetryPolicy retries rate limited and failed requests with exponential backoff and jitter
+type RetryPolicy struct {
+	// MaxAttempts counts the first request, 1 never retries
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy makes up to 4 attempts, backing off from half a second to 10 seconds
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 4, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second}
+}
+
+// delay returns how long to wait before retry n (from 1): BaseDelay doubled each retry up to MaxDelay, half of it
+// random so clients that failed together don't retry together
+func (r RetryPolicy) delay(n int) time.Duration {
+	d := r.MaxDelay
+
+	if n < 32 && r.BaseDelay<<(n-1) < r.MaxDelay {
+		d = r.BaseDelay << (n - 1)
+	}
+
+	return d/2 + time.Duration(jitter(int64(d/2)+1))
+}
+
+var jitterRand = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// jitter returns a random number in [0, n)
+func jitter(n int64) int64 {
+	jitterRand.Lock()
+	defer jitterRand.Unlock()
+
+	return jitterRand.Int63n(n)
+}
+
+// TokenBucket paces requests to a rate, allowing bursts of up to Burst requests after a quiet spell
+type TokenBucket struct {
+	// Rate is the requests per second refilled into the bucket
+	Rate  float64
+	Burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewTokenBucket allows rate requests a second, in bursts of up to burst, starting full
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{Rate: rate, Burst: float64(burst), tokens: float64(burst)}
+}
+
+// Wait blocks until a request may be sent, or ctx is done
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	d := b.reserve()
+
+	if d == 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// reserve takes a token, returning how long to wait until it has refilled
+func (b *TokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.now != nil {
+		now = b.now()
+	}
+
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.Rate
+
+		if b.tokens > b.Burst {
+			b.tokens = b.Burst
+		}
+	}
+
+	b.last = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.Rate * float64(time.Second))
+}
+
+// fetch sends the request, with form as the url encoded body when set, retrying per y.Retry, and decodes the
+// JSON response into x
+func (y *YahooAPIClient) fetch(method, endpoint string, form url.Values, x interface{}) error {
+	body, err := y.body(method, endpoint, form)
+
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, x); err != nil {
+		return fmt.Errorf("%s %s: %w: %v", method, endpoint, ErrMalformedPayload, err)
+	}
+
+	return nil
+}
+
+// body sends the request, with form as the url encoded body when set, retrying per y.Retry, and returns the body
+// of the successful response as it was sent
+func (y *YahooAPIClient) body(method, endpoint string, form url.Values) ([]byte, error) {
+	ctx := y.context()
+	retry := y.Retry
+
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+
+	var err error
+
+	for attempt := 1; attempt <= retry.MaxAttempts; attempt++ {
+		var body []byte
+		var wait time.Duration
+
+		body, wait, err = y.send(ctx, method, endpoint, form)
+
+		if err == nil {
+			return body, nil
+		}
+
+		var httpErr *HTTPError
+		if ctx.Err() != nil || (errors.As(err, &httpErr) && !httpErr.retryable()) || attempt == retry.MaxAttempts {
+			break
+		}
+
+		if wait == 0 {
+			wait = retry.delay(attempt)
+		}
+
+		// A quota that resets later than we would back off for won't be lifted by waiting
+		if wait > retry.MaxDelay {
+			break
+		}
+
+		t := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	return nil, err
+}
+
+// send makes one request, returning the body of a successful response, or the error and how long the server
+// asked to wait before trying again
+func (y *YahooAPIClient) send(ctx context.Context, method, endpoint string, form url.Values) ([]byte, time.Duration, error) {
+	if y.Limiter != nil {
+		if err := y.Limiter.Wait(ctx); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var payload io.Reader
+	if form != nil {
+		payload = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, payload)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if form != nil {
+		req.Header.Add("content-type", "application/x-www-form-urlencoded")
+	}
+
+	req.Header.Add("X-RapidAPI-Key", y.Key)
+	req.Header.Add("X-RapidAPI-Host", y.Host)
+
+	client := y.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s %s: %w", method, endpoint, err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		snippet := string(body)
+		if len(snippet) > 200 {
+			snippet = snippet[:200]
+		}
+
+		return nil, retryAfter(res.Header.Get("Retry-After")), &HTTPError{Method: method, URL: endpoint, StatusCode: res.StatusCode, Body: snippet}
+	}
+
+	return body, 0, nil
+}
+
+// retryAfter reads a Retry-After header in seconds, 0 when missing
+func retryAfter(header string) time.Duration {
+	s, err := strconv.Atoi(strings.TrimSpace(header))
+
+	if err != nil || s < 0 {
+		return 0
+	}
+
+	return time.Duration(s) * time.Second
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// statusServer answers with each status in turn, then the bundled balance sheet, counting the requests
+func statusServer(t *testing.T, statuses ...int) (*YahooAPIClient, *int) {
+	hits := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+
+		if hits <= len(statuses) {
+			w.WriteHeader(statuses[hits-1])
+			w.Write([]byte(`{"message":"no"}`))
+			return
+		}
+
+		b, _ := os.ReadFile("./json/fixtures/yahoo-rapidapi/AAPL/balance.json")
+		w.Write(b)
+	}))
+
+	t.Cleanup(srv.Close)
+
+	retry := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+	return &YahooAPIClient{Origin: srv.URL, Retry: retry}, &hits
+}
+
+func TestYahooAPIClientRetriesRateLimits(t *testing.T) {
+	// Arrange
+	y, hits := statusServer(t, http.StatusTooManyRequests, http.StatusBadGateway)
+
+	// Act
+	x, err := y.GetBalanceSheet("AAPL")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, x.Root)
+	assert.Equal(t, 3, *hits)
+}
+
+func TestYahooAPIClientGivesUpAfterMaxAttempts(t *testing.T) {
+	// Arrange
+	y, hits := statusServer(t, http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests)
+
+	// Act
+	_, err := y.GetBalanceSheet("AAPL")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.Equal(t, 3, *hits)
+}
+
+func TestYahooAPIClientTypedErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		err    error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusNotFound, ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		// Arrange
+		y, hits := statusServer(t, tt.status)
+
+		// Act
+		_, err := y.GetBalanceSheet("AAPL")
+
+		// Assert
+		assert.ErrorIs(t, err, tt.err)
+		assert.Equal(t, 1, *hits, "%d is not retried", tt.status)
+	}
+}
+
+func TestYahooAPIClientMalformedPayload(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html>`))
+	}))
+	defer srv.Close()
+	y := &YahooAPIClient{Origin: srv.URL}
+
+	// Act
+	_, err := y.GetIncomeStatement("AAPL")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrMalformedPayload)
+}
+
+func TestYahooAPIClientCancelled(t *testing.T) {
+	// Arrange
+	y, _ := statusServer(t, http.StatusServiceUnavailable)
+	y.Retry.BaseDelay, y.Retry.MaxDelay = time.Minute, time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, err := y.WithContext(ctx).GetBalanceSheet("AAPL")
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	// Arrange
+	r := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	// Act
+	first, third, tenth := r.delay(1), r.delay(3), r.delay(10)
+
+	// Assert
+	assert.GreaterOrEqual(t, first, 50*time.Millisecond)
+	assert.LessOrEqual(t, first, 100*time.Millisecond)
+	assert.GreaterOrEqual(t, third, 200*time.Millisecond)
+	assert.LessOrEqual(t, third, 400*time.Millisecond)
+	assert.LessOrEqual(t, tenth, time.Second)
+}
+
+func TestTokenBucket(t *testing.T) {
+	// Arrange
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewTokenBucket(2, 2)
+	b.now = func() time.Time { return now }
+
+	// Act
+	first, second, third := b.reserve(), b.reserve(), b.reserve()
+	now = now.Add(2 * time.Second)
+	rested := b.reserve()
+
+	// Assert
+	assert.Zero(t, first)
+	assert.Zero(t, second)
+	assert.Equal(t, 500*time.Millisecond, third)
+	assert.Zero(t, rested)
+}
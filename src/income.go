@@ -7,14 +7,12 @@ import (
 	"time"
 
 	"github.com/leekchan/accounting"
+	"github.com/shopspring/decimal"
 )
 
+// IncomeStatement holds every fiscal year of income statements that was loaded
 type IncomeStatement struct {
-	Y2018 *YearIncomeStatement
-	Y2019 *YearIncomeStatement
-	Y2020 *YearIncomeStatement
-	Y2021 *YearIncomeStatement
-	// Y2022 *YearIncomeStatement
+	Series[*YearIncomeStatement]
 }
 
 type Logger struct {
@@ -22,52 +20,47 @@ type Logger struct {
 }
 
 type YearIncomeStatement struct {
-	Year                         int
-	totalRevenue                 int64
-	costOfRevenue                int64
-	sellingGeneralAdministrative int64
-	interestExpense              int64
-	researchDevelopment          int64
-	incomeBeforeTax              int64
-	incomeTaxExpense             int64
-	netEarnings                  int64
+	Year int
+	End  time.Time
+	// Currency the amounts are reported in, each line item is an exact amount of it
+	Currency                     string
+	totalRevenue                 decimal.Decimal
+	costOfRevenue                decimal.Decimal
+	sellingGeneralAdministrative decimal.Decimal
+	interestExpense              decimal.Decimal
+	researchDevelopment          decimal.Decimal
+	incomeBeforeTax              decimal.Decimal
+	incomeTaxExpense             decimal.Decimal
+	netEarnings                  decimal.Decimal
 	sharesOutstanding            int64
+	// latestShares is set when sharesOutstanding is today's count standing in for the period's
+	latestShares bool
 }
 
-type IncomeMargins[T any] interface {
-	GrossProfitMargin() T
-	SellingGeneralAdministrativeMargin() T
-	InterestExpenseMargin() T
+type IncomeOptions struct {
+	logging bool
 }
 
-type IncomeAttributes[T any] interface {
-	GrossProfit() T
+func NewIncomeStatement(y *YahooIncomeStatementV15, ysi *YahooStockInfo) *IncomeStatement {
+	return newIncomeStatement(y.Root.IncomeStatementHistory, ysi)
 }
 
-type IncomeOptions struct {
-	logging bool
+// NewQuarterlyIncomeStatement creates an IncomeStatement of the quarters in the Yahoo payload
+func NewQuarterlyIncomeStatement(y *YahooIncomeStatementV15, ysi *YahooStockInfo) *IncomeStatement {
+	return newIncomeStatement(y.Quarterly.IncomeStatementHistory, ysi)
 }
 
-func NewIncomeStatement(y *YahooIncomeStatementV15, ysi *YahooStockInfo) *IncomeStatement {
+func newIncomeStatement(history []YahooIncomeStatementHistory, ysi *YahooStockInfo) *IncomeStatement {
 	s := &IncomeStatement{}
 
-	for _, x := range y.Root.IncomeStatementHistory {
+	for _, x := range history {
 		y := NewYearIncomeStatement(x, ysi)
 
-		switch y.Year {
-		case 2018:
-			s.Y2018 = y
-		case 2019:
-			s.Y2019 = y
-		case 2020:
-			s.Y2020 = y
-		case 2021:
-			s.Y2021 = y
-		// case 2022:
-		// 	s.Y2022 = y
-		default:
-			panic("Year not implemented yet")
+		if y == nil {
+			continue
 		}
+
+		s.Set(y.End, y)
 	}
 
 	return s
@@ -77,137 +70,163 @@ func NewYearIncomeStatement(yish YahooIncomeStatementHistory, ysi *YahooStockInf
 	y := &YearIncomeStatement{}
 
 	// Clone raw values
-	y.costOfRevenue = yish.CostOfRevenue.Raw
-	y.totalRevenue = yish.TotalRevenue.Raw
-	y.sellingGeneralAdministrative = yish.SellingGeneralAdministrative.Raw
-	y.interestExpense = yish.InterestExpense.Raw
-	y.researchDevelopment = yish.ResearchDevelopment.Raw
-	y.incomeBeforeTax = yish.IncomeBeforeTax.Raw
-	y.incomeTaxExpense = yish.IncomeTaxExpense.Raw
-	y.netEarnings = yish.NetEarnings.Raw
-
-	y.sharesOutstanding = ysi.Root.SharesOutstanding
+	y.costOfRevenue = decimal.New(yish.CostOfRevenue.Raw, 0)
+	y.totalRevenue = decimal.New(yish.TotalRevenue.Raw, 0)
+	y.sellingGeneralAdministrative = decimal.New(yish.SellingGeneralAdministrative.Raw, 0)
+	y.interestExpense = decimal.New(yish.InterestExpense.Raw, 0)
+	y.researchDevelopment = decimal.New(yish.ResearchDevelopment.Raw, 0)
+	y.incomeBeforeTax = decimal.New(yish.IncomeBeforeTax.Raw, 0)
+	y.incomeTaxExpense = decimal.New(yish.IncomeTaxExpense.Raw, 0)
+	y.netEarnings = decimal.New(yish.NetEarnings.Raw, 0)
+
+	// Prefer the share count of the year, falling back to today's
+	y.sharesOutstanding = yish.DilutedAverageShares.Raw
+
+	if y.sharesOutstanding == 0 && ysi != nil {
+		y.sharesOutstanding = ysi.Root.SharesOutstanding
+		y.latestShares = true
+	}
 
 	d, err := time.Parse("2006-01-02", yish.EndDate.Fmt)
-	y.Year = d.Year()
 
 	if err != nil {
 		return nil
 	}
 
+	y.End = d
+	y.Year = d.Year()
+
+	if ysi != nil {
+		y.Currency = ysi.Root.FinancialCurrency
+	}
+
 	return y
 }
 
 // TotalRevenue
-func (I *YearIncomeStatement) TotalRevenue() int64 {
-	return I.totalRevenue
+func (I *YearIncomeStatement) TotalRevenue() Money {
+	return I.money(I.totalRevenue)
 }
 
 // CostOfRevenue
-func (I *YearIncomeStatement) CostOfRevenue() int64 {
-	return I.costOfRevenue
+func (I *YearIncomeStatement) CostOfRevenue() Money {
+	return I.money(I.costOfRevenue)
 }
 
 // GrossProfit
-func (I *YearIncomeStatement) GrossProfit() int64 {
-	return I.TotalRevenue() - I.CostOfRevenue()
+func (I *YearIncomeStatement) GrossProfit() Money {
+	return I.money(I.totalRevenue.Sub(I.costOfRevenue))
 }
 
-// GrossProfitMargin
-func (I *YearIncomeStatement) GrossProfitMargin() float64 {
-	return float64(I.GrossProfit()) / float64(I.TotalRevenue())
+// GrossProfitMargin (GrossProfit / TotalRevenue)
+func (I *YearIncomeStatement) GrossProfitMargin() (decimal.Decimal, error) {
+	return Ratio(I.GrossProfit(), I.TotalRevenue())
 }
 
 // SellingGeneralAdministrative
-func (I *YearIncomeStatement) SellingGeneralAdministrative() int64 {
-	return I.sellingGeneralAdministrative
+func (I *YearIncomeStatement) SellingGeneralAdministrative() Money {
+	return I.money(I.sellingGeneralAdministrative)
 }
 
-// SellingGeneralAdministrativeMargin
-func (I *YearIncomeStatement) SellingGeneralAdministrativeMargin() float64 {
-	return float64(I.SellingGeneralAdministrative()) / float64(I.GrossProfit())
+// SellingGeneralAdministrativeMargin (SellingGeneralAdministrative / GrossProfit)
+func (I *YearIncomeStatement) SellingGeneralAdministrativeMargin() (decimal.Decimal, error) {
+	return Ratio(I.SellingGeneralAdministrative(), I.GrossProfit())
 }
 
 // InterestExpense
-func (I *YearIncomeStatement) InterestExpense() int64 {
-	return I.interestExpense
+func (I *YearIncomeStatement) InterestExpense() Money {
+	return I.money(I.interestExpense)
 }
 
-// InterestExpenseMargin
-func (I *YearIncomeStatement) InterestExpenseMargin() float64 {
-	return float64(I.InterestExpense()) / float64(I.GrossProfit())
+// InterestExpenseMargin (InterestExpense / GrossProfit)
+func (I *YearIncomeStatement) InterestExpenseMargin() (decimal.Decimal, error) {
+	return Ratio(I.InterestExpense(), I.GrossProfit())
 }
 
 // ResearchDevelopment
-func (I *YearIncomeStatement) ResearchDevelopment() int64 {
-	return I.researchDevelopment
+func (I *YearIncomeStatement) ResearchDevelopment() Money {
+	return I.money(I.researchDevelopment)
 }
 
-// ResearchDevelopmentMargin
-func (I *YearIncomeStatement) ResearchDevelopmentMargin() float64 {
-	return float64(I.ResearchDevelopment()) / float64(I.GrossProfit())
+// ResearchDevelopmentMargin (ResearchDevelopment / GrossProfit)
+func (I *YearIncomeStatement) ResearchDevelopmentMargin() (decimal.Decimal, error) {
+	return Ratio(I.ResearchDevelopment(), I.GrossProfit())
 }
 
 // IncomeBeforeTax
-func (I *YearIncomeStatement) IncomeBeforeTax() int64 {
-	return I.incomeBeforeTax
+func (I *YearIncomeStatement) IncomeBeforeTax() Money {
+	return I.money(I.incomeBeforeTax)
 }
 
 // IncomeTaxExpense
-func (I *YearIncomeStatement) IncomeTaxExpense() int64 {
-	return I.incomeTaxExpense
+func (I *YearIncomeStatement) IncomeTaxExpense() Money {
+	return I.money(I.incomeTaxExpense)
 }
 
 // NetEarnings calculates (Gross Profit - Expenses - Taxes)
-func (I *YearIncomeStatement) NetEarnings() int64 {
-	return I.netEarnings
+func (I *YearIncomeStatement) NetEarnings() Money {
+	return I.money(I.netEarnings)
 }
 
-// SharesOutstanding returns the total amount of available shares
+// SharesOutstanding returns the diluted weighted average shares of the year, or the latest shares outstanding
+// when the provider has no count per year
 func (I *YearIncomeStatement) SharesOutstanding() int64 {
 	return I.sharesOutstanding
 }
 
+// HasOwnShares reports whether SharesOutstanding is the period's own share count, rather than today's standing in
+// for it
+func (I *YearIncomeStatement) HasOwnShares() bool {
+	return !I.latestShares
+}
+
 // PerShareEarnings returns the total earnings per share (NetEarnings / SharesOutstanding)
-func (I *YearIncomeStatement) PerShareEarnings() float64 {
-	return float64(I.NetEarnings()) / float64(I.SharesOutstanding())
+func (I *YearIncomeStatement) PerShareEarnings() (Money, error) {
+	return I.NetEarnings().Div(I.SharesOutstanding())
 }
 
-func (I *IncomeStatement) PerShareEarningsMean() float64 {
-	x := []float64{I.Y2018.PerShareEarnings(), I.Y2019.PerShareEarnings(), I.Y2020.PerShareEarnings(), I.Y2021.PerShareEarnings()}
+// money returns an amount of the statement in its currency
+func (I *YearIncomeStatement) money(v decimal.Decimal) Money {
+	return Money{Amount: v, Currency: I.Currency}
+}
 
-	var sum float64 = 0
-	for _, e := range x {
-		sum += e
+// HasOwnShares reports whether every period has its own share count, rather than today's standing in for it
+func (I *IncomeStatement) HasOwnShares() bool {
+	for _, y := range I.Values() {
+		if !y.HasOwnShares() {
+			return false
+		}
 	}
 
-	return sum / float64(len(x))
+	return true
 }
 
-func (I *IncomeStatement) PerShareEarningsSTD() float64 {
-	mean := I.PerShareEarningsMean()
+// PerShareEarningsMean returns the mean PerShareEarnings over every loaded year with shares outstanding
+func (I *IncomeStatement) PerShareEarningsMean() float64 {
+	x, _ := incomeRatios(I, (*YearIncomeStatement).perShareEarnings)
+
+	return mean(x)
+}
 
+// PerShareEarningsSTD calculate the Standard Deviation of PerShareEarnings over every loaded year
+func (I *IncomeStatement) PerShareEarningsSTD() float64 {
 	ac := accounting.Accounting{Symbol: "$", Precision: 2}
 
-	fmt.Println("2018 PerShareEarnings", ac.FormatMoney(I.Y2018.PerShareEarnings()))
-	fmt.Println("2019 PerShareEarnings", ac.FormatMoney(I.Y2019.PerShareEarnings()))
-	fmt.Println("2020 PerShareEarnings", ac.FormatMoney(I.Y2020.PerShareEarnings()))
-	fmt.Println("2021 PerShareEarnings", ac.FormatMoney(I.Y2021.PerShareEarnings()))
+	x := []float64{}
 
-	// deviations
-	d2018 := float64(I.Y2018.PerShareEarnings()) - mean
-	d2019 := float64(I.Y2019.PerShareEarnings()) - mean
-	d2020 := float64(I.Y2020.PerShareEarnings()) - mean
-	d2021 := float64(I.Y2021.PerShareEarnings()) - mean
+	for _, y := range I.Values() {
+		eps, err := y.PerShareEarnings()
 
-	// fmt.Println("2018 PerShareEarnings", ac.FormatMoney(I.Y2018.PerShareEarnings()))
-	// fmt.Println("2019 PerShareEarnings", ac.FormatMoney(I.Y2019.PerShareEarnings()))
-	// fmt.Println("2020 PerShareEarnings", ac.FormatMoney(I.Y2020.PerShareEarnings()))
-	// fmt.Println("2021 PerShareEarnings", ac.FormatMoney(I.Y2021.PerShareEarnings()))
+		if err != nil {
+			fmt.Println(y.Year, "PerShareEarnings", err)
+			continue
+		}
 
-	sample := 4 - 1
+		fmt.Println(y.Year, "PerShareEarnings", ac.FormatMoney(eps.Float64()))
+		x = append(x, eps.Float64())
+	}
 
-	variance := (math.Pow(d2018, 2) + math.Pow(d2019, 2) + math.Pow(d2020, 2) + math.Pow(d2021, 2)) / float64(sample)
+	variance := sampleVariance(x)
 	fmt.Println("Variance", ac.FormatMoney(variance))
 
 	// standard deviation
@@ -217,75 +236,58 @@ func (I *IncomeStatement) PerShareEarningsSTD() float64 {
 	return std
 }
 
+// NetEarningsMean returns the mean NetEarnings over every loaded year
 func (I *IncomeStatement) NetEarningsMean() float64 {
-	x := []int64{I.Y2018.NetEarnings(), I.Y2019.NetEarnings(), I.Y2020.NetEarnings(), I.Y2021.NetEarnings()}
+	x := []float64{}
 
-	// fmt.Println("2018 NetEarnings", ac.FormatMoney(I.Y2018.NetEarnings()))
-	// fmt.Println("2019 NetEarnings", ac.FormatMoney(I.Y2019.NetEarnings()))
-	// fmt.Println("2020 NetEarnings", ac.FormatMoney(I.Y2020.NetEarnings()))
-	// fmt.Println("2021 NetEarnings", ac.FormatMoney(I.Y2021.NetEarnings()))
-
-	var sum float64 = 0
-	for _, e := range x {
-		sum += float64(e)
+	for _, y := range I.Values() {
+		x = append(x, y.NetEarnings().Float64())
 	}
 
-	return sum / float64(len(x))
+	return mean(x)
 }
 
-// NetEarningsSTD calculate the Standard Deviation of NetEarnings over x years
+// NetEarningsSTD calculate the Standard Deviation of NetEarnings over every loaded year
 func (I *IncomeStatement) NetEarningsSTD() float64 {
-	// ac := accounting.Accounting{Symbol: "$", Precision: 2 }
-
-	mean := I.NetEarningsMean()
-	// fmt.Println("Mean", ac.FormatMoney(mean))
-
-	// deviations
-	d2018 := float64(I.Y2018.NetEarnings()) - mean
-	d2019 := float64(I.Y2019.NetEarnings()) - mean
-	d2020 := float64(I.Y2020.NetEarnings()) - mean
-	d2021 := float64(I.Y2021.NetEarnings()) - mean
+	x := []float64{}
 
-	sample := 4 - 1
-
-	variance := (math.Pow(d2018, 2) + math.Pow(d2019, 2) + math.Pow(d2020, 2) + math.Pow(d2021, 2)) / float64(sample)
-	// fmt.Println("Variance", ac.FormatMoney(variance))
+	for _, y := range I.Values() {
+		x = append(x, y.NetEarnings().Float64())
+	}
 
 	// standard deviation
-	std := math.Sqrt(variance)
-	// fmt.Println("std", ac.FormatMoney(std))
-
-	return std
+	return math.Sqrt(sampleVariance(x))
 }
 
+// NetEarnings rates the multi-year history of NetEarnings, looking for a steady upward trend
 func (I *IncomeStatement) NetEarnings() Rating {
-	ac := accounting.Accounting{Symbol: "$", Precision: 2}
-
-	std := I.NetEarningsSTD()
-
-	fmt.Println("std", ac.FormatMoney(std))
+	return I.NetEarningsTrend(nil).Rating
+}
 
-	// have to be within standard deviation?
-	// and not below it consistently?
-	// standard deviation within x
-	// also compare to competitors ??
+// NetEarningsTrend measures the history of NetEarnings against rules, nil uses the default profile
+func (I *IncomeStatement) NetEarningsTrend(rules *TrendProfile) Trend {
+	values, ends := incomeValues(I, func(y *YearIncomeStatement) float64 { return y.NetEarnings().Float64() })
 
-	return BAD
+	return newTrend(values, ends, rules)
 }
 
+// PerShareEarnings rates the multi-year history of PerShareEarnings, looking for a steady upward trend
 func (I *IncomeStatement) PerShareEarnings() Rating {
+	return I.PerShareEarningsTrend(nil).Rating
+}
 
-	I.Y2018.PerShareEarnings()
-	I.Y2019.PerShareEarnings()
-	I.Y2020.PerShareEarnings()
-	I.Y2021.PerShareEarnings()
-	// I.Y2022.PerShareEarnings()
+// PerShareEarningsTrend measures the history of PerShareEarnings against rules, nil uses the default profile
+func (I *IncomeStatement) PerShareEarningsTrend(rules *TrendProfile) Trend {
+	values, ends := incomeRatios(I, (*YearIncomeStatement).perShareEarnings)
 
-	// compare all, look for upward trend
+	return newTrend(values, ends, rules)
+}
 
-	// also compare to competitors ??
+// perShareEarnings is PerShareEarnings as a plain amount, for trends and statistics
+func (I *YearIncomeStatement) perShareEarnings() (decimal.Decimal, error) {
+	eps, err := I.PerShareEarnings()
 
-	return BAD
+	return eps.Amount, err
 }
 
 func (I *Logger) GrossProfit() {
@@ -293,19 +295,23 @@ func (I *Logger) GrossProfit() {
 }
 
 func (I *Logger) GrossProfitMargin() {
-	log.Printf("GrossProfitMargin (TotalRevenue / Gross Profit)%f\n", I.statement.GrossProfitMargin())
+	r, err := I.statement.GrossProfitMargin()
+	logRatio("GrossProfitMargin (Gross Profit / TotalRevenue)", r, err)
 }
 
 func (I *Logger) SellingGeneralAdministrativeMargin() {
-	log.Printf("SellingGeneralAdministrativeMargin (SellingGeneralAdministrative / Gross Profit)%f\n", I.statement.SellingGeneralAdministrativeMargin())
+	r, err := I.statement.SellingGeneralAdministrativeMargin()
+	logRatio("SellingGeneralAdministrativeMargin (SellingGeneralAdministrative / Gross Profit)", r, err)
 }
 
 func (I *Logger) InterestExpenseMargin() {
-	log.Printf("InterestExpenseMargin (Interest Expense / Gross Profit)%f\n", I.statement.InterestExpenseMargin())
+	r, err := I.statement.InterestExpenseMargin()
+	logRatio("InterestExpenseMargin (Interest Expense / Gross Profit)", r, err)
 }
 
 func (I *Logger) ResearchDevelopmentMargin() {
-	log.Printf("ResearchDevelopmentMargin ( Research Development / Gross Profit)%f\n", I.statement.ResearchDevelopmentMargin())
+	r, err := I.statement.ResearchDevelopmentMargin()
+	logRatio("ResearchDevelopmentMargin ( Research Development / Gross Profit)", r, err)
 }
 
 func (I *Logger) IncomeBeforeTax() {
@@ -321,5 +327,16 @@ func (I *Logger) SharesOutstanding() {
 }
 
 func (I *Logger) PerShareEarnings() {
-	log.Printf("PerShareEarnings ( Net Earnings / Shares Outstanding)%f\n", I.statement.PerShareEarnings())
+	eps, err := I.statement.PerShareEarnings()
+	logRatio("PerShareEarnings ( Net Earnings / Shares Outstanding)", eps.Amount, err)
+}
+
+// logRatio logs a ratio, or why it isn't meaningful
+func logRatio(name string, d decimal.Decimal, err error) {
+	if err != nil {
+		log.Println(name, err)
+		return
+	}
+
+	log.Println(name, d.StringFixed(6))
 }
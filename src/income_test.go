@@ -9,27 +9,112 @@ import (
 func TestNetEarnings(t *testing.T) {
 	// Arrange
 	m := YahooMockClient{}
-	y, _ := m.GetIncomeStatement("AAPC")
-	s, _ := m.GetStockInfo("AAPC")
+	y, _ := m.GetIncomeStatement("AAPL")
+	s, _ := m.GetStockInfo("AAPL")
 
 	x := NewIncomeStatement(y, s)
 
-	// Act`
-	x.NetEarnings()
+	// Act
+	r := x.NetEarnings()
 
 	// Assert
-	assert.True(t, true)
+	assert.Equal(t, OK, r)
+}
+
+func TestSharesOutstandingFallsBackToLatest(t *testing.T) {
+	// Arrange
+	m := YahooMockClient{}
+	y, _ := m.GetIncomeStatement("AAPL")
+	s, _ := m.GetStockInfo("AAPL")
+
+	// Act
+	x := NewIncomeStatement(y, s)
+
+	// Assert
+	// Yahoo's income statement history has no share count per year
+	assert.Equal(t, s.Root.SharesOutstanding, x.Year(2018).SharesOutstanding())
+	assert.Equal(t, s.Root.SharesOutstanding, x.Year(2021).SharesOutstanding())
+	assert.False(t, x.Year(2021).HasOwnShares())
+	assert.False(t, x.HasOwnShares())
+}
+
+func TestSharesOutstandingPerYear(t *testing.T) {
+	// Arrange
+	h := YahooIncomeStatementHistory{
+		EndDate:              YahooIncomeStatementItem{Fmt: "2018-09-29"},
+		NetEarnings:          YahooIncomeStatementItem{Raw: 100},
+		DilutedAverageShares: YahooIncomeStatementItem{Raw: 20},
+	}
+	s := &YahooStockInfo{}
+	s.Root.SharesOutstanding = 10
+
+	// Act
+	y := NewYearIncomeStatement(h, s)
+	eps, err := y.PerShareEarnings()
+
+	// Assert
+	assert.Equal(t, int64(20), y.SharesOutstanding())
+	assert.True(t, y.HasOwnShares())
+	assert.NoError(t, err)
+	assert.Equal(t, "5", eps.String())
+}
+
+func TestPerShareEarnings(t *testing.T) {
+	// Arrange
+	m := YahooMockClient{}
+	y, _ := m.GetIncomeStatement("AAPL")
+	s, _ := m.GetStockInfo("AAPL")
+
+	x := NewIncomeStatement(y, s)
+
+	// Act
+	tr := x.PerShareEarningsTrend(nil)
+
+	// Assert
+	assert.Equal(t, OK, x.PerShareEarnings())
+	assert.Equal(t, 1, tr.DownYears)
+	assert.InDelta(t, 0.1673, tr.CAGR, 0.0001)
 }
 
 func TestNetEarningsSTD(t *testing.T) {
+	// Arrange
+	m := YahooMockClient{}
+	y, _ := m.GetIncomeStatement("AAPL")
+	s, _ := m.GetStockInfo("AAPL")
 
+	x := NewIncomeStatement(y, s)
+
+	// Act
+	std := x.NetEarningsSTD()
+
+	// Assert
+	assert.Equal(t, 4, x.Len())
+	assert.InDelta(t, 18721859745.58, std, 1)
+}
+
+func TestIncomeStatementLoadsAnyFiscalYear(t *testing.T) {
+	// Arrange
+	y := &YahooIncomeStatementV15{}
+	y.Root.IncomeStatementHistory = []YahooIncomeStatementHistory{
+		{EndDate: YahooIncomeStatementItem{Fmt: "2022-09-24"}, NetEarnings: YahooIncomeStatementItem{Raw: 99803000000}},
+		{EndDate: YahooIncomeStatementItem{Fmt: "2017-09-30"}, NetEarnings: YahooIncomeStatementItem{Raw: 48351000000}},
+	}
+	s := &YahooStockInfo{}
+
+	// Act
+	x := NewIncomeStatement(y, s)
+
+	// Assert
+	assert.Equal(t, 2, x.Len())
+	assert.Equal(t, 2017, x.Values()[0].Year)
+	assert.Equal(t, int64(99803000000), x.Year(2022).NetEarnings().Units())
 }
 
 func TestPerShareEarningsSTD(t *testing.T) {
 	// Arrange
 	m := YahooMockClient{}
-	y, _ := m.GetIncomeStatement("AAPC")
-	s, _ := m.GetStockInfo("AAPC")
+	y, _ := m.GetIncomeStatement("AAPL")
+	s, _ := m.GetStockInfo("AAPL")
 
 	x := NewIncomeStatement(y, s)
 
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ErrNotEnoughData is returned when a check needs a statement or year that wasn't loaded
+var ErrNotEnoughData = errors.New("not enough data")
+
+// LegitimacyRating checks whether reported earnings can be trusted
+type LegitimacyRating struct {
+	income   *IncomeStatement
+	balance  *BalanceSheet
+	cashFlow *CashFlowStatement
+	country  string
+	profile  *RatingProfile
+	// periodsPerYear annualises the flows of quarters, and picks the quarter a year earlier to compare with
+	periodsPerYear int
+}
+
+// TaxCheck compares the tax a business reports against what its country would charge
+type TaxCheck struct {
+	Rating        Rating
+	EffectiveRate float64
+	StatutoryRate float64
+	// Ratio is EffectiveRate / StatutoryRate
+	Ratio float64
+}
+
+// AccrualsCheck measures how much of net earnings never turned into cash
+type AccrualsCheck struct {
+	Rating             Rating
+	NetEarnings        Money
+	OperatingCashFlow  Money
+	AverageTotalAssets Money
+	AccrualsRatio      float64
+}
+
+// BeneishCheck is the Beneish M-Score and the eight indices it is built from
+type BeneishCheck struct {
+	Rating Rating
+	MScore float64
+	// Days Sales in Receivables Index
+	DSRI float64
+	// Gross Margin Index
+	GMI float64
+	// Asset Quality Index
+	AQI float64
+	// Sales Growth Index
+	SGI float64
+	// Depreciation Index
+	DEPI float64
+	// Sales, General and Administrative expenses Index
+	SGAI float64
+	// Leverage Index
+	LVGI float64
+	// Total Accruals to Total Assets
+	TATA float64
+}
+
+// statutoryRate is a headline corporate tax rate, in force from the given year
+type statutoryRate struct {
+	from int
+	rate float64
+}
+
+// statutoryRates are headline corporate income tax rates by country, as named by Yahoo
+var statutoryRates = map[string][]statutoryRate{
+	"United States":  {{from: 0, rate: 0.35}, {from: 2018, rate: 0.21}},
+	"United Kingdom": {{from: 0, rate: 0.20}, {from: 2017, rate: 0.19}, {from: 2023, rate: 0.25}},
+	"Ireland":        {{from: 0, rate: 0.125}},
+	"Germany":        {{from: 0, rate: 0.30}},
+	"France":         {{from: 0, rate: 0.3333}, {from: 2020, rate: 0.28}, {from: 2022, rate: 0.25}},
+	"Netherlands":    {{from: 0, rate: 0.25}, {from: 2022, rate: 0.258}},
+	"Canada":         {{from: 0, rate: 0.265}},
+	"Japan":          {{from: 0, rate: 0.3062}},
+	"Australia":      {{from: 0, rate: 0.30}},
+	"Switzerland":    {{from: 0, rate: 0.147}},
+}
+
+// StatutoryTaxRate returns the headline corporate tax rate of country in year
+func StatutoryTaxRate(country string, year int) (float64, bool) {
+	for name, rates := range statutoryRates {
+		if !strings.EqualFold(name, country) {
+			continue
+		}
+
+		rate := rates[0].rate
+		for _, r := range rates {
+			if year >= r.from {
+				rate = r.rate
+			}
+		}
+
+		return rate, true
+	}
+
+	return 0, false
+}
+
+// NewLegitimacyRating checks the statements of f against profile, nil uses the default profile
+func NewLegitimacyRating(f *Fundamentals, profile *RatingProfile) *LegitimacyRating {
+	if profile == nil {
+		profile = DefaultRatingProfile()
+	}
+
+	l := &LegitimacyRating{income: f.Income, balance: f.Balance, cashFlow: f.CashFlow, profile: profile, periodsPerYear: f.PeriodsPerYear()}
+
+	if f.Stock != nil {
+		l.country = f.Stock.Root.Country
+	}
+
+	return l
+}
+
+// end returns the end of the period in year, from whichever statement has it
+func (I *LegitimacyRating) end(year int) time.Time {
+	for _, ends := range [][]time.Time{I.income.Ends(), I.balance.Ends(), I.cashFlow.Ends()} {
+		for _, end := range ends {
+			if end.Year() == year {
+				return end
+			}
+		}
+	}
+
+	return time.Time{}
+}
+
+// yearEarlier returns the end in ends a year before end: the period before for yearly periods, the same quarter a
+// year earlier for quarters so seasons don't skew the comparison. Zero when it wasn't loaded
+func (I *LegitimacyRating) yearEarlier(ends []time.Time, end time.Time) time.Time {
+	for i, e := range ends {
+		if !e.Equal(end) {
+			continue
+		}
+
+		if I.periodsPerYear <= 1 {
+			if i > 0 {
+				return ends[i-1]
+			}
+
+			return time.Time{}
+		}
+
+		// Quarter ends drift by a few days a year, and a missing quarter would put the wrong one here
+		if j := i - I.periodsPerYear; j >= 0 && math.Abs(yearsBetween(ends[j], end)-1) < 0.1 {
+			return ends[j]
+		}
+
+		return time.Time{}
+	}
+
+	return time.Time{}
+}
+
+// IncomeTaxExpense compares the effective tax rate (Income Tax Expense / Income Before Tax) with the statutory rate
+func (I *LegitimacyRating) IncomeTaxExpense(year int) (TaxCheck, error) {
+	return I.IncomeTaxExpenseAt(I.end(year))
+}
+
+// IncomeTaxExpenseAt is IncomeTaxExpense for the period ending at end
+func (I *LegitimacyRating) IncomeTaxExpenseAt(end time.Time) (TaxCheck, error) {
+	income := I.income.At(end)
+	year := end.Year()
+
+	if income == nil {
+		return TaxCheck{}, fmt.Errorf("%d income statement: %w", year, ErrNotEnoughData)
+	}
+
+	statutory, ok := StatutoryTaxRate(I.country, year)
+
+	if !ok {
+		return TaxCheck{}, fmt.Errorf("statutory tax rate of %q: %w", I.country, ErrNotEnoughData)
+	}
+
+	if income.IncomeBeforeTax().Amount.IsNegative() {
+		return TaxCheck{}, fmt.Errorf("%d: no tax is due on a loss: %w", year, ErrNotEnoughData)
+	}
+
+	effective, err := ratioFloat(Ratio(income.IncomeTaxExpense(), income.IncomeBeforeTax()))
+
+	if err != nil {
+		return TaxCheck{}, fmt.Errorf("%d effective tax rate: %w", year, err)
+	}
+
+	c := TaxCheck{EffectiveRate: effective, StatutoryRate: statutory}
+
+	c.Ratio = c.EffectiveRate / c.StatutoryRate
+	c.Rating, _ = I.profile.Rate("effectiveTaxRateToStatutory", c.Ratio)
+
+	return c, nil
+}
+
+// AccrualsRatio compares net earnings with operating cash flow, scaled by the average of opening and closing total assets
+func (I *LegitimacyRating) AccrualsRatio(year int) (AccrualsCheck, error) {
+	return I.AccrualsRatioAt(I.end(year))
+}
+
+// AccrualsRatioAt is AccrualsRatio for the period ending at end
+func (I *LegitimacyRating) AccrualsRatioAt(end time.Time) (AccrualsCheck, error) {
+	cash := I.cashFlow.At(end)
+	balance := I.balance.At(end)
+	year := end.Year()
+
+	if cash == nil || balance == nil {
+		return AccrualsCheck{}, fmt.Errorf("%d cash flow and balance sheet: %w", year, ErrNotEnoughData)
+	}
+
+	netEarnings := cash.NetEarnings()
+
+	if income := I.income.At(end); income != nil {
+		netEarnings = income.NetEarnings()
+	}
+
+	c := AccrualsCheck{
+		NetEarnings:        netEarnings,
+		OperatingCashFlow:  cash.TotalCashFromOperatingActivities(),
+		AverageTotalAssets: balance.TotalAssets(),
+	}
+
+	var err error
+
+	if opening := I.balance.Before(end); opening != nil {
+		if c.AverageTotalAssets, err = average(opening.TotalAssets(), c.AverageTotalAssets); err != nil {
+			return c, fmt.Errorf("%d average total assets: %w", year, err)
+		}
+	}
+
+	accruals, err := c.NetEarnings.Sub(c.OperatingCashFlow)
+
+	if err != nil {
+		return c, fmt.Errorf("%d accruals: %w", year, err)
+	}
+
+	if c.AccrualsRatio, err = ratioFloat(Ratio(accruals, c.AverageTotalAssets)); err != nil {
+		return c, fmt.Errorf("%d accruals ratio: %w", year, err)
+	}
+
+	// A quarter's accruals are annualised to rate against the yearly bands
+	c.AccrualsRatio *= float64(I.periodsPerYear)
+
+	c.Rating, _ = I.profile.Rate("accrualsRatio", c.AccrualsRatio)
+
+	return c, nil
+}
+
+// BeneishMScore estimates the likelihood of earnings manipulation from year and the year before
+func (I *LegitimacyRating) BeneishMScore(year int) (BeneishCheck, error) {
+	return I.BeneishMScoreAt(I.end(year))
+}
+
+// BeneishMScoreAt is BeneishMScore for the period ending at end and the period a year before, the same quarter a
+// year earlier for quarters
+func (I *LegitimacyRating) BeneishMScoreAt(end time.Time) (BeneishCheck, error) {
+	i, pi := I.income.At(end), I.income.At(I.yearEarlier(I.income.Ends(), end))
+	b, pb := I.balance.At(end), I.balance.At(I.yearEarlier(I.balance.Ends(), end))
+	c, pc := I.cashFlow.At(end), I.cashFlow.At(I.yearEarlier(I.cashFlow.Ends(), end))
+	year := end.Year()
+
+	if i == nil || pi == nil || b == nil || pb == nil || c == nil || pc == nil {
+		return BeneishCheck{}, fmt.Errorf("%d and the period a year before: %w", year, ErrNotEnoughData)
+	}
+
+	// The M-Score is a statistical model, its indices are estimates in float64
+	sales, prevSales := i.TotalRevenue().Float64(), pi.TotalRevenue().Float64()
+	assets := b.TotalAssets().Float64()
+
+	margin, err := ratioFloat(i.GrossProfitMargin())
+
+	if err != nil {
+		return BeneishCheck{}, fmt.Errorf("%d gross margin: %w", year, err)
+	}
+
+	prevMargin, err := ratioFloat(pi.GrossProfitMargin())
+
+	if err != nil {
+		return BeneishCheck{}, fmt.Errorf("%d gross margin: %w", pi.Year, err)
+	}
+
+	depreciationRate := func(c *YearCashFlowStatement, b *YearBalanceSheet) float64 {
+		return c.Depreciation().Float64() / (c.Depreciation().Float64() + b.PropertyPlantEquipment().Float64())
+	}
+
+	assetQuality := func(b *YearBalanceSheet) float64 {
+		return 1 - (b.TotalCurrentAssets().Float64()+b.PropertyPlantEquipment().Float64())/b.TotalAssets().Float64()
+	}
+
+	leverage := func(b *YearBalanceSheet) float64 {
+		return (b.TotalCurrentLiabilities().Float64() + b.LongTermDebt().Float64()) / b.TotalAssets().Float64()
+	}
+
+	m := BeneishCheck{
+		DSRI: (b.NetReceivables().Float64() / sales) / (pb.NetReceivables().Float64() / prevSales),
+		GMI:  prevMargin / margin,
+		AQI:  assetQuality(b) / assetQuality(pb),
+		SGI:  sales / prevSales,
+		DEPI: depreciationRate(pc, pb) / depreciationRate(c, b),
+		SGAI: (i.SellingGeneralAdministrative().Float64() / sales) / (pi.SellingGeneralAdministrative().Float64() / prevSales),
+		LVGI: leverage(b) / leverage(pb),
+		// A quarter's accruals are annualised, the model was fitted to yearly figures
+		TATA: (i.NetEarnings().Float64() - c.TotalCashFromOperatingActivities().Float64()) / assets * float64(I.periodsPerYear),
+	}
+
+	m.MScore = -4.84 + 0.920*m.DSRI + 0.528*m.GMI + 0.404*m.AQI + 0.892*m.SGI + 0.115*m.DEPI -
+		0.172*m.SGAI + 4.679*m.TATA - 0.327*m.LVGI
+
+	if math.IsNaN(m.MScore) || math.IsInf(m.MScore, 0) {
+		return m, fmt.Errorf("%d: M-Score, a line item is zero: %w", year, ErrNotMeaningful)
+	}
+
+	m.Rating, _ = I.profile.Rate("beneishMScore", m.MScore)
+
+	return m, nil
+}
+
+// Print writes every legitimacy check for every loaded period as a table
+func (I *LegitimacyRating) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	label := periodLabels(I.income.Ends())
+
+	fmt.Fprintln(tw, "Year\tCheck\tValue\tRating\tDetail")
+
+	for _, end := range I.income.Ends() {
+		year := label(end)
+
+		if c, err := I.IncomeTaxExpenseAt(end); err != nil {
+			fmt.Fprintf(tw, "%s\tEffective tax rate\t\t\t%v\n", year, err)
+		} else {
+			fmt.Fprintf(tw, "%s\tEffective tax rate\t%.4f\t%s\tstatutory %.4f\n", year, c.EffectiveRate, c.Rating, c.StatutoryRate)
+		}
+
+		if c, err := I.AccrualsRatioAt(end); err != nil {
+			fmt.Fprintf(tw, "%s\tAccruals ratio\t\t\t%v\n", year, err)
+		} else {
+			fmt.Fprintf(tw, "%s\tAccruals ratio\t%.4f\t%s\tnet earnings %s, operating cash flow %s\n", year, c.AccrualsRatio, c.Rating, c.NetEarnings.Amount, c.OperatingCashFlow.Amount)
+		}
+
+		if c, err := I.BeneishMScoreAt(end); err != nil {
+			fmt.Fprintf(tw, "%s\tBeneish M-Score\t\t\t%v\n", year, err)
+		} else {
+			fmt.Fprintf(tw, "%s\tBeneish M-Score\t%.4f\t%s\tDSRI %.2f GMI %.2f AQI %.2f SGI %.2f DEPI %.2f SGAI %.2f LVGI %.2f TATA %.3f\n",
+				year, c.MScore, c.Rating, c.DSRI, c.GMI, c.AQI, c.SGI, c.DEPI, c.SGAI, c.LVGI, c.TATA)
+		}
+	}
+
+	tw.Flush()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLegitimacyIncomeTaxExpense(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	l := NewLegitimacyRating(f, nil)
+
+	// Act
+	c, err := l.IncomeTaxExpense(2021)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.InDelta(t, 14527.0/109207.0, c.EffectiveRate, 0.0001)
+	assert.Equal(t, 0.21, c.StatutoryRate)
+	assert.Equal(t, OK, c.Rating)
+}
+
+func TestLegitimacyIncomeTaxExpenseUnknownCountry(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	f.Stock.Root.Country = "Atlantis"
+	l := NewLegitimacyRating(f, nil)
+
+	// Act
+	_, err := l.IncomeTaxExpense(2021)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotEnoughData)
+}
+
+func TestLegitimacyIncomeTaxExpenseWithoutIncome(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	f.Income.Year(2021).incomeBeforeTax = decimal.Zero
+	l := NewLegitimacyRating(f, nil)
+
+	// Act
+	_, err := l.IncomeTaxExpense(2021)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotMeaningful)
+}
+
+func TestLegitimacyAccrualsRatio(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	l := NewLegitimacyRating(f, nil)
+
+	// Act
+	c, err := l.AccrualsRatio(2021)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.InDelta(t, (94680.0-104038.0)/((323888.0+351002.0)/2), c.AccrualsRatio, 0.0001)
+	assert.Equal(t, GOOD, c.Rating)
+}
+
+func TestLegitimacyBeneishMScore(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	l := NewLegitimacyRating(f, nil)
+
+	// Act
+	m, err := l.BeneishMScore(2021)
+	_, firstYearErr := l.BeneishMScore(2018)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.InDelta(t, -2.2503, m.MScore, 0.0001)
+	assert.InDelta(t, 365817.0/274515.0, m.SGI, 0.0001)
+	assert.Equal(t, GOOD, m.Rating)
+	assert.ErrorIs(t, firstYearErr, ErrNotEnoughData)
+}
+
+func TestLegitimacyQuarters(t *testing.T) {
+	// Arrange
+	f, _ := LoadQuarterlyFundamentals(&YahooMockClient{}, "AAPL")
+	l := NewLegitimacyRating(f, nil)
+	end := time.Date(2021, 12, 25, 0, 0, 0, 0, time.UTC)
+	quarters := []time.Time{
+		time.Date(2020, 12, 26, 0, 0, 0, 0, time.UTC), time.Date(2021, 3, 27, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 6, 26, 0, 0, 0, 0, time.UTC), time.Date(2021, 9, 25, 0, 0, 0, 0, time.UTC), end,
+	}
+
+	latest := time.Date(2022, 6, 25, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	c, err := l.AccrualsRatioAt(latest)
+	_, beneishErr := l.BeneishMScoreAt(latest)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.InDelta(t, 4*(19442.0-22892.0)/343485.5, c.AccrualsRatio, 0.0001, "a quarter's accruals are annualised")
+	assert.ErrorIs(t, beneishErr, ErrNotEnoughData, "the mock has no quarter a year earlier to compare with")
+	assert.Equal(t, quarters[0], l.yearEarlier(quarters, end))
+	assert.True(t, l.yearEarlier(quarters[1:], end).IsZero())
+}
+
+func TestStatutoryTaxRate(t *testing.T) {
+	// Act
+	us2017, _ := StatutoryTaxRate("United States", 2017)
+	uk2023, _ := StatutoryTaxRate("united kingdom", 2023)
+	_, ok := StatutoryTaxRate("Atlantis", 2023)
+
+	// Assert
+	assert.Equal(t, 0.35, us2017)
+	assert.Equal(t, 0.25, uk2023)
+	assert.False(t, ok)
+}
@@ -1,57 +1,344 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
 
 	"github.com/urfave/cli/v2"
 )
 
 type config struct {
 	businessSymbol string
+	provider       string
+	dir            string
+	profile        string
+	period         string
+	cacheDir       string
+	noCache        bool
+	record         string
+	store          string
+	storeProvider  string
+	asOf           string
+	timeout        time.Duration
+	rateLimit      float64
+	dcf            DCFOptions
+	equityBond     EquityBondOptions
+	ownerEarnings  OwnerEarningsOptions
+	restatement    RestatementOptions
 }
 
 func main() {
-	var conf config
+	conf := config{dcf: DefaultDCFOptions(), equityBond: DefaultEquityBondOptions(), restatement: DefaultRestatementOptions(), timeout: 30 * time.Second, rateLimit: 5}
 
 	app := &cli.App{
 		Name:  "Finance",
 		Usage: "Analyse investment viability of a publicly traded business",
 		Action: func(c *cli.Context) error {
-			return run(conf)
+			// Not a required flag, so the cache commands run without it
+			if conf.businessSymbol == "" {
+				return fmt.Errorf("required flag \"symbol\" not set")
+			}
+
+			if c.IsSet("growth") {
+				g := c.Float64("growth")
+				conf.dcf.Growth = &g
+			}
+
+			return run(c.Context, conf)
 
 		},
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:        "symbol",
 				Aliases:     []string{"s"},
-				Required:    true,
 				Destination: &conf.businessSymbol,
-				Usage:       "Business symbol of the company to analyse. Example: AAPC for Apple",
+				Usage:       "Business symbol of the company to analyse. Example: AAPL for Apple",
+			},
+			&cli.StringFlag{
+				Name:        "provider",
+				Aliases:     []string{"p"},
+				Value:       "mock",
+				Destination: &conf.provider,
+				Usage:       "Source of the fundamentals: mock, yahoo-rapidapi, file, replay, edgar, csv or store",
+			},
+			&cli.StringFlag{
+				Name:        "dir",
+				Destination: &conf.dir,
+				Usage:       "Directory of saved Yahoo responses (file), recorded symbols (replay), SEC companyfacts JSON (edgar) or typed up statements (csv)",
+			},
+			&cli.StringFlag{
+				Name:        "period",
+				Value:       string(Annual),
+				Destination: &conf.period,
+				Usage:       "Statements to analyse: annual, quarterly or ttm (annual plus the trailing twelve months)",
+			},
+			&cli.StringFlag{
+				Name:        "profile",
+				Destination: &conf.profile,
+				Usage:       "YAML rating profile of GOOD/OK/BAD bands, see profiles/default.yaml",
+			},
+			&cli.Float64Flag{
+				Name:  "growth",
+				Usage: "Yearly free cash flow growth for the DCF valuation, e.g. 0.05. Defaults to the historical CAGR",
+			},
+			&cli.Float64Flag{
+				Name:        "discount-rate",
+				Value:       conf.dcf.DiscountRate,
+				Destination: &conf.dcf.DiscountRate,
+				Usage:       "Return required of the investment, used to discount future free cash flow",
+			},
+			&cli.Float64Flag{
+				Name:        "terminal-multiple",
+				Value:       conf.dcf.TerminalMultiple,
+				Destination: &conf.dcf.TerminalMultiple,
+				Usage:       "Multiple of the final year's free cash flow the business is valued at",
+			},
+			&cli.IntFlag{
+				Name:        "dcf-years",
+				Value:       conf.dcf.Years,
+				Destination: &conf.dcf.Years,
+				Usage:       "Years of free cash flow to project",
+			},
+			&cli.Float64Flag{
+				Name:        "bond-yield",
+				Value:       conf.equityBond.BondYield,
+				Destination: &conf.equityBond.BondYield,
+				Usage:       "Government bond yield the equity's earnings yield is compared against",
+			},
+			&cli.BoolFlag{
+				Name:        "estimate-maintenance-capex",
+				Destination: &conf.ownerEarnings.EstimateMaintenanceCapitalExpenditures,
+				Usage:       "Estimate maintenance capital expenditures for owner earnings from the historical capex / revenue ratio, rather than counting all capex",
+			},
+			&cli.StringFlag{
+				Name:        "cache-dir",
+				Value:       DefaultCacheDir(),
+				Destination: &conf.cacheDir,
+				Usage:       "Directory responses from yahoo-rapidapi are cached in",
+			},
+			&cli.DurationFlag{
+				Name:        "timeout",
+				Value:       conf.timeout,
+				Destination: &conf.timeout,
+				Usage:       "Longest to wait for each yahoo-rapidapi request",
+			},
+			&cli.Float64Flag{
+				Name:        "rate-limit",
+				Value:       conf.rateLimit,
+				Destination: &conf.rateLimit,
+				Usage:       "Requests per second sent to yahoo-rapidapi, to match the RapidAPI plan",
+			},
+			&cli.BoolFlag{
+				Name:        "no-cache",
+				Destination: &conf.noCache,
+				Usage:       "Fetch every response from the provider, neither reading nor writing the cache",
+			},
+			&cli.StringFlag{
+				Name:        "record",
+				Destination: &conf.record,
+				Usage:       "Directory to record the provider's responses in, as <provider>/<SYMBOL>/<endpoint>.json fixtures for replay",
+			},
+			&cli.StringFlag{
+				Name:        "store",
+				Destination: &conf.store,
+				Usage:       "SQLite database every fetched statement and quote is saved in, and the store provider reads",
+			},
+			&cli.StringFlag{
+				Name:        "store-provider",
+				Value:       "yahoo-rapidapi",
+				Destination: &conf.storeProvider,
+				Usage:       "Provider whose stored fundamentals the store provider reads, each provider's fetches are kept apart",
+			},
+			&cli.StringFlag{
+				Name:        "as-of",
+				Destination: &conf.asOf,
+				Usage:       "Read the store as it was at this date (end of day, UTC) or RFC 3339 time, rather than the latest",
+			},
+			&cli.Float64Flag{
+				Name:        "material-restatement",
+				Value:       conf.restatement.Material,
+				Destination: &conf.restatement.Material,
+				Usage:       "Relative change of a stored line item from which a restatement is flagged as material",
+			},
+		},
+		Commands: []*cli.Command{
+			{
+				Name:  "cache",
+				Usage: "Manage cached provider responses",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "List every cached response and whether it is still fresh",
+						Action: func(c *cli.Context) error {
+							x, err := NewCache(conf.cacheDir).Entries()
+
+							if err != nil {
+								return err
+							}
+
+							PrintCacheEntries(os.Stdout, x)
+
+							return nil
+						},
+					},
+					{
+						Name:      "clear",
+						Usage:     "Remove cached responses, of one symbol or all of them",
+						ArgsUsage: "[symbol]",
+						Action: func(c *cli.Context) error {
+							return NewCache(conf.cacheDir).Clear(c.Args().First())
+						},
+					},
+				},
+			},
+			{
+				Name:  "store",
+				Usage: "Inspect the store of fetched fundamentals",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "list",
+						Usage:     "List every stored fetch, of one symbol or all of them",
+						ArgsUsage: "[symbol]",
+						Action: func(c *cli.Context) error {
+							if conf.store == "" {
+								return fmt.Errorf("--store is required")
+							}
+
+							s, err := OpenStore(conf.store)
+
+							if err != nil {
+								return err
+							}
+
+							defer s.Close()
+
+							x, err := s.Fetches(c.Args().First())
+
+							if err != nil {
+								return err
+							}
+
+							PrintStoredFetches(os.Stdout, x)
+
+							return nil
+						},
+					},
+				},
 			},
 		},
 	}
 
-	app.Run(os.Args)
+	// Ctrl-C cancels requests in flight rather than waiting out their retries
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := app.RunContext(ctx, os.Args); err != nil {
+		log.Fatal(err)
+	}
 
 }
 
-func run(conf config) error {
-	// m := YahooAPIClient{}
-	m := YahooMockClient{}
+func run(ctx context.Context, conf config) error {
+	profile, err := LoadRatingProfile(conf.profile)
+
+	if err != nil {
+		return err
+	}
+
+	period, err := ParseStatementPeriod(conf.period)
 
-	y, err := m.GetIncomeStatement(conf.businessSymbol)
+	if err != nil {
+		return err
+	}
+
+	p, err := NewProvider(ctx, conf)
 
 	if err != nil {
 		return err
 	}
 
-	s, err := m.GetStockInfo(conf.businessSymbol)
+	if c, ok := p.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	// Fetches before this run are the previous ones restatements are found against
+	started := time.Now()
+	var store *Store
+
+	if conf.store != "" && conf.provider != "store" {
+		store, err = OpenStore(conf.store)
+
+		if err != nil {
+			return err
+		}
+
+		defer store.Close()
+
+		p = storingProvider(p, conf.provider, store)
+	}
+
+	if conf.record != "" {
+		p = &RecordingProvider{Provider: p, Dir: filepath.Join(conf.record, conf.provider)}
+	}
+
+	f, err := LoadFundamentalsFor(p, conf.businessSymbol, period)
 
 	if err != nil {
 		return err
 	}
 
-	NewIncomeStatement(y, s)
+	if !f.Income.HasOwnShares() {
+		fmt.Fprintf(os.Stderr, "warning: %s has no share count per year from %s, per share metrics use today's shares outstanding\n", f.Symbol, conf.provider)
+	}
+
+	score := ScoreValue(f, profile)
+	score.Print(os.Stdout)
+
+	if store != nil {
+		if r, err := store.Restatement(conf.provider, f, started.Add(-time.Nanosecond), conf.restatement); err != nil {
+			fmt.Println(err)
+		} else if r != nil {
+			r.Print(os.Stdout)
+		}
+	}
+
+	PrintDuPonts(os.Stdout, DuPonts(f))
+	NewTrendRating(f, profile).Print(os.Stdout)
+	NewLegitimacyRating(f, profile).Print(os.Stdout)
+
+	if oe, err := NewOwnerEarnings(f, conf.ownerEarnings); err != nil {
+		fmt.Println(err)
+	} else {
+		oe.Print(os.Stdout)
+	}
+
+	if r, err := NewRetainedEarnings(f, profile); err != nil {
+		fmt.Println(err)
+	} else {
+		r.Print(os.Stdout)
+	}
+
+	v, err := NewValuation(f, conf.dcf)
+
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		v.Print(os.Stdout)
+	}
+
+	if e, err := NewEquityBond(f, conf.equityBond); err != nil {
+		fmt.Println(err)
+	} else {
+		e.Print(os.Stdout)
+	}
+
+	NewSignal(v, score, f.Stock, profile.Signals).Print(os.Stdout)
 
 	return nil
 }
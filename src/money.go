@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrNotMeaningful is returned for a ratio that can't be read as a number, e.g. a margin of zero revenue
+var ErrNotMeaningful = errors.New("not meaningful")
+
+// ErrCurrencyMismatch is returned when amounts in different currencies are combined
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money is an exact amount in one currency, e.g. USD. An empty currency is one the provider didn't report
+type Money struct {
+	Amount   decimal.Decimal
+	Currency string
+}
+
+// NewMoney creates Money from a whole currency amount, as statements report them
+func NewMoney(units int64, currency string) Money {
+	return Money{Amount: decimal.New(units, 0), Currency: currency}
+}
+
+// Add returns m + o
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.same(o); err != nil {
+		return Money{}, err
+	}
+
+	return Money{Amount: m.Amount.Add(o.Amount), Currency: m.Currency}, nil
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.same(o); err != nil {
+		return Money{}, err
+	}
+
+	return Money{Amount: m.Amount.Sub(o.Amount), Currency: m.Currency}, nil
+}
+
+// Sum adds up amounts in one currency, zero Money when there are none
+func Sum(x ...Money) (Money, error) {
+	if len(x) == 0 {
+		return Money{}, nil
+	}
+
+	sum := x[0]
+
+	for _, m := range x[1:] {
+		var err error
+
+		if sum, err = sum.Add(m); err != nil {
+			return Money{}, err
+		}
+	}
+
+	return sum, nil
+}
+
+// Div divides m by a count, e.g. of shares. ErrNotMeaningful when n is zero
+func (m Money) Div(n int64) (Money, error) {
+	if n == 0 {
+		return Money{}, fmt.Errorf("%s / 0: %w", m, ErrNotMeaningful)
+	}
+
+	return Money{Amount: m.Amount.Div(decimal.New(n, 0)), Currency: m.Currency}, nil
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{Amount: m.Amount.Neg(), Currency: m.Currency}
+}
+
+// Units returns the amount in whole currency units, as statements report them, dropping any fraction
+func (m Money) Units() int64 {
+	return m.Amount.IntPart()
+}
+
+// Float64 returns the nearest float64 to the amount, for printing and statistics
+func (m Money) Float64() float64 {
+	f, _ := m.Amount.Float64()
+
+	return f
+}
+
+// String formats the amount followed by its currency, e.g. 94680000000 USD
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Amount.String()
+	}
+
+	return m.Amount.String() + " " + m.Currency
+}
+
+func (m Money) same(o Money) error {
+	if m.Currency != o.Currency {
+		return fmt.Errorf("%w: %q and %q", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+
+	return nil
+}
+
+// Ratio divides num by den, both in the same currency. ErrNotMeaningful when den is zero
+func Ratio(num, den Money) (decimal.Decimal, error) {
+	if err := num.same(den); err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	if den.Amount.IsZero() {
+		return decimal.Decimal{}, fmt.Errorf("%s / 0: %w", num, ErrNotMeaningful)
+	}
+
+	return num.Amount.Div(den.Amount), nil
+}
+
+// ratioFloat converts a Ratio into a float64 for rating and statistics
+func ratioFloat(d decimal.Decimal, err error) (float64, error) {
+	if err != nil {
+		return 0, err
+	}
+
+	f, _ := d.Float64()
+
+	return f, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoneyAdd(t *testing.T) {
+	// Arrange
+	a, b := NewMoney(100, "USD"), NewMoney(25, "USD")
+
+	// Act
+	sum, err := a.Add(b)
+	_, mismatch := a.Add(NewMoney(25, "EUR"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "125 USD", sum.String())
+	assert.ErrorIs(t, mismatch, ErrCurrencyMismatch)
+}
+
+func TestMoneyDiv(t *testing.T) {
+	// Arrange
+	m := NewMoney(100, "USD")
+
+	// Act
+	third, err := m.Div(3)
+	_, zeroErr := m.Div(0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "33.33", third.Amount.StringFixed(2))
+	assert.ErrorIs(t, zeroErr, ErrNotMeaningful)
+}
+
+func TestRatioIsExact(t *testing.T) {
+	// Arrange
+	num, den := NewMoney(3, "USD"), NewMoney(10, "USD")
+
+	// Act
+	r, err := Ratio(num, den)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "0.3", r.String())
+}
+
+func TestRatioNotMeaningful(t *testing.T) {
+	// Arrange
+	num, den := NewMoney(3, "USD"), NewMoney(0, "USD")
+
+	// Act
+	_, err := Ratio(num, den)
+	_, mismatch := Ratio(num, NewMoney(10, "GBP"))
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotMeaningful)
+	assert.ErrorIs(t, mismatch, ErrCurrencyMismatch)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// OwnerEarningsOptions are the assumptions behind owner earnings
+type OwnerEarningsOptions struct {
+	// EstimateMaintenanceCapitalExpenditures splits capital expenditures into maintenance and growth using the
+	// historical capital expenditures / revenue ratio, otherwise all capital expenditures count as maintenance
+	EstimateMaintenanceCapitalExpenditures bool
+}
+
+// OwnerEarnings holds Buffett's owner earnings for every fiscal year with a cash flow statement
+type OwnerEarnings struct {
+	Series[*YearOwnerEarnings]
+	OwnerEarningsOptions
+	// CapitalExpendituresToRevenue is the mean historical ratio maintenance capital expenditures are estimated from
+	CapitalExpendituresToRevenue float64
+}
+
+// YearOwnerEarnings is NetEarnings + Depreciation - MaintenanceCapitalExpenditures + WorkingCapitalChange
+type YearOwnerEarnings struct {
+	Year                           int
+	End                            time.Time
+	NetEarnings                    Money
+	Depreciation                   Money
+	CapitalExpenditures            Money
+	MaintenanceCapitalExpenditures Money
+	// WorkingCapitalChange is the sum of the cash flow "Change To ..." rows, negative when working capital absorbed cash
+	WorkingCapitalChange Money
+	OwnerEarnings        Money
+}
+
+// NewOwnerEarnings calculates owner earnings per fiscal year of f
+func NewOwnerEarnings(f *Fundamentals, o OwnerEarningsOptions) (*OwnerEarnings, error) {
+	if f.CashFlow == nil || f.CashFlow.Len() == 0 {
+		return nil, fmt.Errorf("owner earnings: no cash flow statements: %w", ErrNotEnoughData)
+	}
+
+	oe := &OwnerEarnings{OwnerEarningsOptions: o}
+
+	if o.EstimateMaintenanceCapitalExpenditures {
+		ratios := []float64{}
+
+		for _, c := range f.CashFlow.Values() {
+			if i := f.Income.At(c.End); i != nil && i.TotalRevenue().Amount.IsPositive() {
+				if r, err := ratioFloat(Ratio(c.CapitalExpenditures().Neg(), i.TotalRevenue())); err == nil {
+					ratios = append(ratios, r)
+				}
+			}
+		}
+
+		if len(ratios) == 0 {
+			return nil, fmt.Errorf("owner earnings: no revenue to estimate maintenance capital expenditures from: %w", ErrNotEnoughData)
+		}
+
+		oe.CapitalExpendituresToRevenue = mean(ratios)
+	}
+
+	for _, p := range f.CashFlow.Periods() {
+		c := p.Value
+
+		workingCapital, err := Sum(c.ChangeToAccountReceivables(), c.ChangeToLiabilities(), c.ChangeToInventory(), c.ChangeToOperatingActivities())
+
+		if err != nil {
+			return nil, fmt.Errorf("owner earnings: %d working capital: %w", c.Year, err)
+		}
+
+		y := &YearOwnerEarnings{
+			Year:                 c.Year,
+			End:                  c.End,
+			NetEarnings:          c.NetEarnings(),
+			Depreciation:         c.Depreciation(),
+			CapitalExpenditures:  c.CapitalExpenditures().Neg(),
+			WorkingCapitalChange: workingCapital,
+		}
+
+		if i := f.Income.At(c.End); i != nil {
+			y.NetEarnings = i.NetEarnings()
+		}
+
+		y.MaintenanceCapitalExpenditures = y.CapitalExpenditures
+
+		if o.EstimateMaintenanceCapitalExpenditures {
+			y.MaintenanceCapitalExpenditures = oe.maintenance(f, c.End, y.CapitalExpenditures)
+		}
+
+		if y.OwnerEarnings, err = Sum(y.NetEarnings, y.Depreciation, y.MaintenanceCapitalExpenditures.Neg(), y.WorkingCapitalChange); err != nil {
+			return nil, fmt.Errorf("owner earnings: %d: %w", c.Year, err)
+		}
+
+		oe.Set(p.End, y)
+	}
+
+	return oe, nil
+}
+
+// maintenance estimates the capital expenditures needed to hold revenue at the period before's level,
+// anything spent beyond that is growth. It never exceeds what was actually spent
+func (O *OwnerEarnings) maintenance(f *Fundamentals, end time.Time, spent Money) Money {
+	i := f.Income.Before(end)
+
+	if i == nil {
+		i = f.Income.At(end)
+	}
+
+	if i == nil {
+		return spent
+	}
+
+	m := Money{Amount: i.TotalRevenue().Amount.Mul(decimal.NewFromFloat(O.CapitalExpendituresToRevenue)).Round(0), Currency: spent.Currency}
+
+	if m.Amount.GreaterThan(spent.Amount) {
+		return spent
+	}
+
+	return m
+}
+
+// Mean returns the mean owner earnings over every loaded year
+func (O *OwnerEarnings) Mean() float64 {
+	x := []float64{}
+
+	for _, y := range O.Values() {
+		x = append(x, y.OwnerEarnings.Float64())
+	}
+
+	return mean(x)
+}
+
+// Print writes owner earnings for every loaded year as a table
+func (O *OwnerEarnings) Print(w io.Writer) {
+	if O.EstimateMaintenanceCapitalExpenditures {
+		fmt.Fprintf(w, "Owner earnings, maintenance capital expenditures estimated at %.2f%% of revenue\n", O.CapitalExpendituresToRevenue*100)
+	} else {
+		fmt.Fprintln(w, "Owner earnings, all capital expenditures counted as maintenance")
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	label := periodLabels(O.Ends())
+
+	fmt.Fprintln(tw, "Year\tNet Earnings\tDepreciation\tMaintenance Capex\tWorking Capital Change\tOwner Earnings")
+
+	for _, y := range O.Values() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", label(y.End), y.NetEarnings.Amount, y.Depreciation.Amount,
+			y.MaintenanceCapitalExpenditures.Amount, y.WorkingCapitalChange.Amount, y.OwnerEarnings.Amount)
+	}
+
+	fmt.Fprintf(tw, "Mean\t\t\t\t\t%.0f\n", O.Mean())
+
+	tw.Flush()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnerEarnings(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+
+	// Act
+	oe, err := NewOwnerEarnings(f, OwnerEarningsOptions{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, oe.Len())
+
+	y := oe.Year(2021)
+	assert.Equal(t, int64(11085000000), y.MaintenanceCapitalExpenditures.Units())
+	assert.Equal(t, int64(-10125000000+14002000000-2642000000-6146000000), y.WorkingCapitalChange.Units())
+	assert.Equal(t, int64(94680000000+11284000000-11085000000-4911000000), y.OwnerEarnings.Units())
+}
+
+func TestOwnerEarningsEstimatedMaintenance(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+
+	// Act
+	oe, err := NewOwnerEarnings(f, OwnerEarningsOptions{EstimateMaintenanceCapitalExpenditures: true})
+
+	// Assert
+	ratio := (13313.0/265595 + 10495.0/260174 + 7309.0/274515 + 11085.0/365817) / 4
+	assert.NoError(t, err)
+	assert.InDelta(t, ratio, oe.CapitalExpendituresToRevenue, 1e-9)
+
+	// Revenue grew in 2021, so part of the capital expenditures were for growth
+	y := oe.Year(2021)
+	assert.InDelta(t, ratio*274515000000, y.MaintenanceCapitalExpenditures.Float64(), 1)
+	assert.Less(t, y.MaintenanceCapitalExpenditures.Units(), y.CapitalExpenditures.Units())
+
+	// 2020's capital expenditures were low against 2019's revenue, maintenance is capped at what was spent
+	assert.Greater(t, ratio*260174000000, 7309000000.0)
+	assert.Equal(t, int64(7309000000), oe.Year(2020).MaintenanceCapitalExpenditures.Units())
+}
+
+func TestOwnerEarningsMean(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	oe, _ := NewOwnerEarnings(f, OwnerEarningsOptions{})
+
+	// Act
+	m := oe.Mean()
+
+	// Assert
+	sum := 0.0
+	for _, y := range oe.Values() {
+		sum += y.OwnerEarnings.Float64()
+	}
+	assert.InDelta(t, sum/4, m, 1)
+}
@@ -0,0 +1,406 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed profiles/default.yaml
+var defaultRatingProfile []byte
+
+// Direction says whether a metric improves as its value rises or falls
+type Direction string
+
+const (
+	HigherIsBetter Direction = "higher"
+	LowerIsBetter  Direction = "lower"
+)
+
+// RatingProfile holds the GOOD/OK/BAD bands of every metric, i.e. an investment strategy
+type RatingProfile struct {
+	Name    string                   `yaml:"name"`
+	Metrics map[string]MetricProfile `yaml:"metrics"`
+	// PassScore is a pointer so a profile can set it to 0
+	PassScore *float64           `yaml:"passScore"`
+	Weights   map[string]float64 `yaml:"weights"`
+	Trend     *TrendProfile      `yaml:"trend"`
+	Signals   *SignalRules       `yaml:"signals"`
+}
+
+// TrendProfile holds the rules a multi-year history must meet to rate GOOD or OK
+type TrendProfile struct {
+	Good TrendRules `yaml:"good"`
+	OK   TrendRules `yaml:"ok"`
+}
+
+// TrendRules are the limits on a multi-year history
+type TrendRules struct {
+	MaxDownYears int     `yaml:"maxDownYears"`
+	MinCAGR      float64 `yaml:"minCAGR"`
+	MaxVariation float64 `yaml:"maxVariation"`
+}
+
+// MetricProfile is the direction and bands of one metric
+type MetricProfile struct {
+	Direction Direction `yaml:"direction"`
+	Bands     []Band    `yaml:"bands"`
+}
+
+// Band rates values from Min (inclusive) to Max (exclusive), a nil bound is open ended. MinExclusive and
+// MaxInclusive move a bound's value into the neighbouring band, and a band with Min equal to Max and MaxInclusive
+// rates that single value
+type Band struct {
+	Rating       Rating   `yaml:"rating"`
+	Min          *float64 `yaml:"min,omitempty"`
+	Max          *float64 `yaml:"max,omitempty"`
+	MinExclusive bool     `yaml:"minExclusive,omitempty"`
+	MaxInclusive bool     `yaml:"maxInclusive,omitempty"`
+}
+
+// ratingMetrics are the metrics a profile can describe
+var ratingMetrics = []string{
+	"grossProfitMargin",
+	"sellingGeneralAdministrativeMargin",
+	"interestExpenseMargin",
+	"researchDevelopmentMargin",
+	"currentRatio",
+	"debtToShareholderEquityRatio",
+	"longTermDebtYearsToRepay",
+	"returnOnShareholdersEquity",
+	"returnOnTotalAssets",
+	"leverage",
+	"buybacksToNetEarnings",
+	"shareCountChange",
+	"effectiveTaxRateToStatutory",
+	"accrualsRatio",
+	"beneishMScore",
+}
+
+// DefaultRatingProfile returns the built-in profile
+func DefaultRatingProfile() *RatingProfile {
+	p, err := ParseRatingProfile(defaultRatingProfile, nil)
+
+	if err != nil {
+		panic(fmt.Sprintf("default rating profile: %v", err))
+	}
+
+	return p
+}
+
+// LoadRatingProfile reads a profile from path, metrics it leaves out keep the default bands.
+// An empty path returns the default profile
+func LoadRatingProfile(path string) (*RatingProfile, error) {
+	if path == "" {
+		return DefaultRatingProfile(), nil
+	}
+
+	b, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := ParseRatingProfile(b, DefaultRatingProfile())
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return p, nil
+}
+
+// ParseRatingProfile reads a YAML profile on top of base (which may be nil) and validates it
+func ParseRatingProfile(b []byte, base *RatingProfile) (*RatingProfile, error) {
+	p := &RatingProfile{Metrics: map[string]MetricProfile{}, Weights: map[string]float64{}}
+
+	if base != nil {
+		p.Name = base.Name
+		p.PassScore = base.PassScore
+		p.Trend = base.Trend
+		p.Signals = base.Signals
+
+		for k, v := range base.Metrics {
+			p.Metrics[k] = v
+		}
+
+		for k, v := range base.Weights {
+			p.Weights[k] = v
+		}
+	}
+
+	var x RatingProfile
+
+	if err := yaml.Unmarshal(b, &x); err != nil {
+		return nil, err
+	}
+
+	if x.Name != "" {
+		p.Name = x.Name
+	}
+
+	if x.PassScore != nil {
+		p.PassScore = x.PassScore
+	}
+
+	if x.Trend != nil {
+		p.Trend = x.Trend
+	}
+
+	if x.Signals != nil {
+		p.Signals = x.Signals
+	}
+
+	for k, v := range x.Metrics {
+		p.Metrics[k] = v
+	}
+
+	for k, v := range x.Weights {
+		p.Weights[k] = v
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Validate checks every metric is known and its bands cover all values exactly once
+func (p *RatingProfile) Validate() error {
+	for name, m := range p.Metrics {
+		if !knownMetric(name) {
+			return fmt.Errorf("unknown metric %q, expected one of %s", name, strings.Join(ratingMetrics, ", "))
+		}
+
+		if err := m.validate(); err != nil {
+			return fmt.Errorf("metric %s: %w", name, err)
+		}
+	}
+
+	for _, name := range ratingMetrics {
+		if _, ok := p.Metrics[name]; !ok {
+			return fmt.Errorf("metric %s: no bands", name)
+		}
+	}
+
+	for name, w := range p.Weights {
+		if scoredMetric(name) == nil {
+			return fmt.Errorf("weight for unknown metric %q", name)
+		}
+
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return fmt.Errorf("weight for %s must be zero or more, got %v", name, w)
+		}
+	}
+
+	if p.PassScore == nil {
+		return fmt.Errorf("passScore: missing")
+	}
+
+	if *p.PassScore < 0 || *p.PassScore > 1 {
+		return fmt.Errorf("passScore must be between 0 and 1, got %v", *p.PassScore)
+	}
+
+	if p.Trend == nil {
+		return fmt.Errorf("trend: no rules")
+	}
+
+	if err := p.Trend.validate(); err != nil {
+		return fmt.Errorf("trend: %w", err)
+	}
+
+	if p.Signals == nil {
+		return fmt.Errorf("signals: no rules")
+	}
+
+	if err := p.Signals.validate(); err != nil {
+		return fmt.Errorf("signals: %w", err)
+	}
+
+	return nil
+}
+
+// Weight returns how much metric counts towards the composite score, 1 unless the profile says otherwise
+func (p *RatingProfile) Weight(metric string) float64 {
+	if w, ok := p.Weights[metric]; ok {
+		return w
+	}
+
+	return 1
+}
+
+// Rate returns the rating of value for metric, and the band it fell in
+func (p *RatingProfile) Rate(metric string, value float64) (Rating, Band) {
+	m, ok := p.Metrics[metric]
+
+	if !ok || math.IsNaN(value) {
+		return BAD, Band{Rating: BAD}
+	}
+
+	for _, b := range m.Bands {
+		if b.Contains(value) {
+			return b.Rating, b
+		}
+	}
+
+	return BAD, Band{Rating: BAD}
+}
+
+// Contains reports whether value falls in the band
+func (b Band) Contains(value float64) bool {
+	if b.Min != nil && (value < *b.Min || (b.MinExclusive && value == *b.Min)) {
+		return false
+	}
+
+	if b.Max != nil && (value > *b.Max || (!b.MaxInclusive && value == *b.Max)) {
+		return false
+	}
+
+	return true
+}
+
+// point reports whether the band rates a single value
+func (b Band) point() bool {
+	return b.Min != nil && b.Max != nil && *b.Min == *b.Max
+}
+
+// String formats the band as a range, e.g. [0.3, 0.8)
+func (b Band) String() string {
+	min, max := "-inf", "+inf"
+	open, close := "[", ")"
+
+	if b.Min != nil {
+		min = fmt.Sprint(*b.Min)
+	}
+
+	if b.Max != nil {
+		max = fmt.Sprint(*b.Max)
+	}
+
+	if b.MinExclusive {
+		open = "("
+	}
+
+	if b.MaxInclusive {
+		close = "]"
+	}
+
+	return fmt.Sprintf("%s %s%s, %s%s", b.Rating, open, min, max, close)
+}
+
+func (m MetricProfile) validate() error {
+	if m.Direction != HigherIsBetter && m.Direction != LowerIsBetter {
+		return fmt.Errorf("direction must be %q or %q, got %q", HigherIsBetter, LowerIsBetter, m.Direction)
+	}
+
+	if len(m.Bands) == 0 {
+		return fmt.Errorf("no bands")
+	}
+
+	bands := append([]Band{}, m.Bands...)
+
+	// A single value band comes before the band that starts just above it
+	sort.SliceStable(bands, func(i, j int) bool {
+		if lowerBound(bands[i]) != lowerBound(bands[j]) {
+			return lowerBound(bands[i]) < lowerBound(bands[j])
+		}
+
+		return !bands[i].MinExclusive && bands[j].MinExclusive
+	})
+
+	if bands[0].Min != nil {
+		return fmt.Errorf("gap below %v", *bands[0].Min)
+	}
+
+	if bands[len(bands)-1].Max != nil {
+		return fmt.Errorf("gap above %v", *bands[len(bands)-1].Max)
+	}
+
+	for i, b := range bands {
+		if b.Rating < GOOD || b.Rating > BAD {
+			return fmt.Errorf("band %s: unknown rating", b)
+		}
+
+		if b.Min != nil && b.Max != nil && *b.Min > *b.Max {
+			return fmt.Errorf("band %s: min must be below max", b)
+		}
+
+		if b.point() && (b.MinExclusive || !b.MaxInclusive) {
+			return fmt.Errorf("band %s: a single value band must include its value", b)
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		prev := bands[i-1]
+
+		if prev.Max == nil || b.Min == nil || *prev.Max > *b.Min || (*prev.Max == *b.Min && prev.MaxInclusive && !b.MinExclusive) {
+			return fmt.Errorf("bands %s and %s overlap", prev, b)
+		}
+
+		if *prev.Max < *b.Min || (!prev.MaxInclusive && b.MinExclusive) {
+			return fmt.Errorf("gap between bands %s and %s", prev, b)
+		}
+
+		// A single value band may rate anything, like a cut-off that belongs to neither side
+		if b.point() {
+			continue
+		}
+
+		for j := i - 1; j > 0 && bands[j].point(); j-- {
+			prev = bands[j-1]
+		}
+
+		// GOOD < OK < BAD, so ratings must fall as values rise when higher is better
+		if m.Direction == HigherIsBetter && b.Rating > prev.Rating {
+			return fmt.Errorf("band %s rates worse than lower band %s, but higher is better", b, prev)
+		}
+
+		if m.Direction == LowerIsBetter && b.Rating < prev.Rating {
+			return fmt.Errorf("band %s rates better than lower band %s, but lower is better", b, prev)
+		}
+	}
+
+	return nil
+}
+
+func (t *TrendProfile) validate() error {
+	if t.Good.MaxDownYears < 0 || t.OK.MaxDownYears < 0 {
+		return fmt.Errorf("maxDownYears must be zero or more")
+	}
+
+	if t.Good.MaxVariation < 0 || t.OK.MaxVariation < 0 {
+		return fmt.Errorf("maxVariation must be zero or more")
+	}
+
+	if t.Good.MaxDownYears > t.OK.MaxDownYears || t.Good.MinCAGR < t.OK.MinCAGR || t.Good.MaxVariation > t.OK.MaxVariation {
+		return fmt.Errorf("good rules must be at least as strict as ok rules")
+	}
+
+	return nil
+}
+
+func lowerBound(b Band) float64 {
+	if b.Min == nil {
+		return math.Inf(-1)
+	}
+
+	return *b.Min
+}
+
+func knownMetric(name string) bool {
+	for _, x := range ratingMetrics {
+		if x == name {
+			return true
+		}
+	}
+
+	return false
+}
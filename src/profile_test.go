@@ -0,0 +1,180 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultRatingProfile(t *testing.T) {
+	// Arrange
+	p := DefaultRatingProfile()
+
+	// Act
+	good, _ := p.Rate("sellingGeneralAdministrativeMargin", 0.29)
+	ok, band := p.Rate("sellingGeneralAdministrativeMargin", 0.31)
+	bad, _ := p.Rate("sellingGeneralAdministrativeMargin", 0.8)
+
+	// Assert
+	assert.Equal(t, GOOD, good)
+	assert.Equal(t, OK, ok)
+	assert.Equal(t, "OK (0.3, 0.79)", band.String())
+	assert.Equal(t, BAD, bad)
+}
+
+// The default profile rates every cut-off the way the literals it replaced did
+func TestDefaultRatingProfileBoundaries(t *testing.T) {
+	tests := []struct {
+		metric string
+		value  float64
+		rating Rating
+	}{
+		{"sellingGeneralAdministrativeMargin", 0.3, BAD},
+		{"sellingGeneralAdministrativeMargin", 0.79, BAD},
+		{"interestExpenseMargin", 0.14, GOOD},
+		{"interestExpenseMargin", 0.15, BAD},
+		{"interestExpenseMargin", 0.2, OK},
+		{"interestExpenseMargin", 0.35, BAD},
+		{"researchDevelopmentMargin", 0.1, BAD},
+		{"researchDevelopmentMargin", 0.2, OK},
+		{"researchDevelopmentMargin", 0.25, BAD},
+		{"currentRatio", 1, BAD},
+		{"currentRatio", 1.01, GOOD},
+		{"debtToShareholderEquityRatio", 0.8, GOOD},
+		{"debtToShareholderEquityRatio", 0.81, BAD},
+	}
+
+	p := DefaultRatingProfile()
+
+	for _, tt := range tests {
+		// Act
+		r, band := p.Rate(tt.metric, tt.value)
+
+		// Assert
+		assert.Equal(t, tt.rating, r, "%s %v in %s", tt.metric, tt.value, band)
+	}
+}
+
+func TestLoadRatingProfileOverridesDefault(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "strict.yaml")
+	os.WriteFile(path, []byte(`
+name: strict
+metrics:
+  debtToShareholderEquityRatio:
+    direction: lower
+    bands:
+      - { rating: GOOD, max: 0.5 }
+      - { rating: OK, min: 0.5, max: 0.8 }
+      - { rating: BAD, min: 0.8 }
+`), 0644)
+
+	// Act
+	p, err := LoadRatingProfile(path)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "strict", p.Name)
+	r, _ := p.Rate("debtToShareholderEquityRatio", 0.6)
+	assert.Equal(t, OK, r)
+	r, _ = p.Rate("currentRatio", 1.2)
+	assert.Equal(t, GOOD, r)
+}
+
+func TestRatingProfileZeroPassScore(t *testing.T) {
+	// Act
+	p, err := ParseRatingProfile([]byte("passScore: 0\n"), DefaultRatingProfile())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Zero(t, *p.PassScore)
+	assert.NotZero(t, *DefaultRatingProfile().PassScore)
+}
+
+func TestRatingProfileValidation(t *testing.T) {
+	tests := map[string]string{
+		"gap": `
+metrics:
+  currentRatio:
+    direction: higher
+    bands:
+      - { rating: BAD, max: 1 }
+      - { rating: GOOD, min: 1.5 }
+`,
+		"overlap": `
+metrics:
+  currentRatio:
+    direction: higher
+    bands:
+      - { rating: BAD, max: 1.5 }
+      - { rating: GOOD, min: 1 }
+`,
+		"open end": `
+metrics:
+  currentRatio:
+    direction: higher
+    bands:
+      - { rating: BAD, max: 1 }
+      - { rating: GOOD, min: 1, max: 3 }
+`,
+		"direction": `
+metrics:
+  currentRatio:
+    direction: lower
+    bands:
+      - { rating: BAD, max: 1 }
+      - { rating: GOOD, min: 1 }
+`,
+		"boundary in both bands": `
+metrics:
+  currentRatio:
+    direction: higher
+    bands:
+      - { rating: BAD, max: 1, maxInclusive: true }
+      - { rating: GOOD, min: 1 }
+`,
+		"boundary in neither band": `
+metrics:
+  currentRatio:
+    direction: higher
+    bands:
+      - { rating: BAD, max: 1 }
+      - { rating: GOOD, min: 1, minExclusive: true }
+`,
+		"single value band without its value": `
+metrics:
+  currentRatio:
+    direction: higher
+    bands:
+      - { rating: BAD, max: 1 }
+      - { rating: OK, min: 1, max: 1 }
+      - { rating: GOOD, min: 1 }
+`,
+		"unknown metric": `
+metrics:
+  pegRatio:
+    direction: lower
+    bands:
+      - { rating: GOOD }
+`,
+		"unknown rating": `
+metrics:
+  currentRatio:
+    direction: higher
+    bands:
+      - { rating: GREAT }
+`,
+	}
+
+	for name, yaml := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := ParseRatingProfile([]byte(yaml), DefaultRatingProfile())
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
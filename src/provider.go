@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// FundamentalsProvider supplies the raw statements and stock info for a business symbol
+type FundamentalsProvider interface {
+	GetIncomeStatement(symbol string) (*YahooIncomeStatementV15, error)
+	GetBalanceSheet(symbol string) (*YahooBalanceSheetV1, error)
+	GetCashFlow(symbol string) (*YahooCashFlowV1, error)
+	GetStockInfo(symbol string) (*YahooStockInfo, error)
+}
+
+// QuarterlyProvider is a FundamentalsProvider that also supplies quarterly balance sheets and cash flow
+// statements. Quarterly income statements come with the income statement payload
+type QuarterlyProvider interface {
+	FundamentalsProvider
+	GetQuarterlyBalanceSheet(symbol string) (*YahooBalanceSheetV1, error)
+	GetQuarterlyCashFlow(symbol string) (*YahooCashFlowV1, error)
+}
+
+// RawProvider returns responses as the provider sent them, before they are decoded, so they can be kept whole
+type RawProvider interface {
+	// GetRawResponse returns the body of the response of endpoint for symbol, endpoint being one of cacheEndpoints
+	GetRawResponse(symbol, endpoint string) ([]byte, error)
+}
+
+// StatementPeriod is the period the loaded statements cover
+type StatementPeriod string
+
+const (
+	// Annual statements, one per fiscal year
+	Annual StatementPeriod = "annual"
+	// Quarterly statements, one per fiscal quarter
+	Quarterly StatementPeriod = "quarterly"
+	// TTM is the annual statements followed by the trailing twelve months to the latest quarter
+	TTM StatementPeriod = "ttm"
+)
+
+// ErrNotYearly is returned by analyses that project years ahead from quarterly figures
+var ErrNotYearly = errors.New("needs yearly figures, use the annual or ttm period")
+
+// ErrInvalidSymbol is returned for a symbol that isn't a plain ticker, so it can't name a path outside a directory
+var ErrInvalidSymbol = errors.New("invalid symbol")
+
+// tickerPattern matches tickers such as AAPL, BRK-B, BRK.B, ^GSPC and EURUSD=X
+var tickerPattern = regexp.MustCompile(`^[A-Z0-9^][A-Z0-9.=^-]{0,19}$`)
+
+// checkSymbol returns symbol upper cased for use in a path, or ErrInvalidSymbol when it isn't a plain ticker
+func checkSymbol(symbol string) (string, error) {
+	s := strings.ToUpper(symbol)
+
+	if !tickerPattern.MatchString(s) || strings.Contains(s, "..") {
+		return "", fmt.Errorf("%q: %w", symbol, ErrInvalidSymbol)
+	}
+
+	return s, nil
+}
+
+// ParseStatementPeriod reads annual, quarterly or ttm
+func ParseStatementPeriod(s string) (StatementPeriod, error) {
+	switch p := StatementPeriod(s); p {
+	case Annual, Quarterly, TTM:
+		return p, nil
+	}
+
+	return "", fmt.Errorf("unknown period %q, expected annual, quarterly or ttm", s)
+}
+
+// Fundamentals are the statements of a business built from a FundamentalsProvider
+type Fundamentals struct {
+	Symbol   string
+	Period   StatementPeriod
+	Income   *IncomeStatement
+	Balance  *BalanceSheet
+	CashFlow *CashFlowStatement
+	Stock    *YahooStockInfo
+}
+
+var (
+	_ FundamentalsProvider = &YahooAPIClient{}
+	_ FundamentalsProvider = &YahooMockClient{}
+	_ FundamentalsProvider = &YahooFileClient{}
+	_ FundamentalsProvider = &EdgarFileClient{}
+	_ FundamentalsProvider = &CSVFileClient{}
+	_ FundamentalsProvider = &CachingProvider{}
+	_ FundamentalsProvider = &RecordingProvider{}
+	_ FundamentalsProvider = &ReplayClient{}
+	_ FundamentalsProvider = &StoringProvider{}
+	_ FundamentalsProvider = &StoreProvider{}
+
+	_ QuarterlyProvider = &YahooAPIClient{}
+	_ QuarterlyProvider = &YahooMockClient{}
+	_ QuarterlyProvider = &YahooFileClient{}
+	_ QuarterlyProvider = &CachingProvider{}
+	_ QuarterlyProvider = &RecordingProvider{}
+	_ QuarterlyProvider = &ReplayClient{}
+	_ QuarterlyProvider = &StoringProvider{}
+	_ QuarterlyProvider = &StoreProvider{}
+
+	_ RawProvider = &YahooAPIClient{}
+	_ RawProvider = &CachingProvider{}
+	_ RawProvider = &YahooMockClient{}
+	_ RawProvider = &YahooFileClient{}
+	_ RawProvider = &ReplayClient{}
+	_ RawProvider = &StoringProvider{}
+)
+
+// NewProvider returns the FundamentalsProvider registered under name
+func NewProvider(ctx context.Context, conf config) (FundamentalsProvider, error) {
+	switch conf.provider {
+	case "mock":
+		return &YahooMockClient{}, nil
+	case "yahoo-rapidapi":
+		y := NewYahooAPIClient().WithContext(ctx)
+		y.HTTP.Timeout = conf.timeout
+
+		// Up to a second's worth of requests at once, 0 turns the limiter off
+		y.Limiter = nil
+		if conf.rateLimit > 0 {
+			y.Limiter = NewTokenBucket(conf.rateLimit, int(math.Ceil(conf.rateLimit)))
+		}
+
+		return cachedProvider(conf, y), nil
+	case "file":
+		if conf.dir == "" {
+			return nil, fmt.Errorf("provider file: --dir is required")
+		}
+
+		return &YahooFileClient{Dir: conf.dir}, nil
+	case "replay":
+		if conf.dir == "" {
+			return nil, fmt.Errorf("provider replay: --dir is required")
+		}
+
+		return &ReplayClient{Dir: conf.dir}, nil
+	case "store":
+		if conf.store == "" {
+			return nil, fmt.Errorf("provider store: --store is required")
+		}
+
+		asOf, err := ParseAsOf(conf.asOf)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s, err := OpenStore(conf.store)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &StoreProvider{Store: s, Provider: conf.storeProvider, AsOf: asOf}, nil
+	case "edgar":
+		if conf.dir == "" {
+			return nil, fmt.Errorf("provider edgar: --dir is required")
+		}
+
+		return &EdgarFileClient{Path: conf.dir}, nil
+	case "csv":
+		if conf.dir == "" {
+			return nil, fmt.Errorf("provider csv: --dir is required")
+		}
+
+		return &CSVFileClient{Dir: conf.dir}, nil
+	}
+
+	return nil, fmt.Errorf("unknown provider %q", conf.provider)
+}
+
+// cachedProvider wraps a provider that fetches over the network in the response cache, unless --no-cache
+func cachedProvider(conf config, p FundamentalsProvider) FundamentalsProvider {
+	if conf.noCache {
+		return p
+	}
+
+	return &CachingProvider{Provider: p, Name: conf.provider, Cache: NewCache(conf.cacheDir)}
+}
+
+// storingProvider saves what p fetches in store. Under a response cache it goes beneath it, so only responses
+// fetched over the network are stored, at the time they were fetched
+func storingProvider(p FundamentalsProvider, name string, store *Store) FundamentalsProvider {
+	if c, ok := p.(*CachingProvider); ok {
+		c.Provider = &StoringProvider{Provider: c.Provider, Name: name, Store: store}
+		return c
+	}
+
+	return &StoringProvider{Provider: p, Name: name, Store: store}
+}
+
+// LoadFundamentals fetches every statement for symbol from p
+func LoadFundamentals(p FundamentalsProvider, symbol string) (*Fundamentals, error) {
+	s, err := p.GetStockInfo(symbol)
+
+	if err != nil {
+		return nil, fmt.Errorf("stock info: %w", err)
+	}
+
+	y, err := p.GetIncomeStatement(symbol)
+
+	if err != nil {
+		return nil, fmt.Errorf("income statement: %w", err)
+	}
+
+	ybs, err := p.GetBalanceSheet(symbol)
+
+	if err != nil {
+		return nil, fmt.Errorf("balance sheet: %w", err)
+	}
+
+	ycf, err := p.GetCashFlow(symbol)
+
+	if err != nil {
+		return nil, fmt.Errorf("cash flow: %w", err)
+	}
+
+	return newFundamentals(symbol, Annual, s, NewIncomeStatement(y, s), NewBalanceSheet(ybs), NewCashFlowStatement(ycf)), nil
+}
+
+// LoadQuarterlyFundamentals fetches every quarterly statement for symbol from p
+func LoadQuarterlyFundamentals(p FundamentalsProvider, symbol string) (*Fundamentals, error) {
+	q, ok := p.(QuarterlyProvider)
+
+	if !ok {
+		return nil, fmt.Errorf("provider %T has no quarterly statements", p)
+	}
+
+	s, err := q.GetStockInfo(symbol)
+
+	if err != nil {
+		return nil, fmt.Errorf("stock info: %w", err)
+	}
+
+	y, err := q.GetIncomeStatement(symbol)
+
+	if err != nil {
+		return nil, fmt.Errorf("income statement: %w", err)
+	}
+
+	ybs, err := q.GetQuarterlyBalanceSheet(symbol)
+
+	if err != nil {
+		return nil, fmt.Errorf("quarterly balance sheet: %w", err)
+	}
+
+	ycf, err := q.GetQuarterlyCashFlow(symbol)
+
+	if err != nil {
+		return nil, fmt.Errorf("quarterly cash flow: %w", err)
+	}
+
+	return newFundamentals(symbol, Quarterly, s, NewQuarterlyIncomeStatement(y, s), NewBalanceSheet(ybs), NewCashFlowStatement(ycf)), nil
+}
+
+// LoadFundamentalsFor fetches the statements for symbol covering period
+func LoadFundamentalsFor(p FundamentalsProvider, symbol string, period StatementPeriod) (*Fundamentals, error) {
+	switch period {
+	case Quarterly:
+		return LoadQuarterlyFundamentals(p, symbol)
+	case TTM:
+		annual, err := LoadFundamentals(p, symbol)
+
+		if err != nil {
+			return nil, err
+		}
+
+		quarterly, err := LoadQuarterlyFundamentals(p, symbol)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return TrailingTwelveMonths(annual, quarterly)
+	}
+
+	return LoadFundamentals(p, symbol)
+}
+
+// newFundamentals joins the statements of symbol, stamping each with the currency of the stock
+func newFundamentals(symbol string, period StatementPeriod, s *YahooStockInfo, income *IncomeStatement, balance *BalanceSheet, cashFlow *CashFlowStatement) *Fundamentals {
+	f := &Fundamentals{Symbol: symbol, Period: period, Income: income, Balance: balance, CashFlow: cashFlow, Stock: s}
+
+	balance.SetCurrency(f.Currency())
+	cashFlow.SetCurrency(f.Currency())
+
+	return f
+}
+
+// Currency returns the currency the statements are reported in, empty when the provider didn't say
+func (f *Fundamentals) Currency() string {
+	if f.Stock == nil {
+		return ""
+	}
+
+	return f.Stock.Root.FinancialCurrency
+}
+
+// PeriodsPerYear is how many of the loaded periods make up a year, flow items are annualised this many times over
+func (f *Fundamentals) PeriodsPerYear() int {
+	if f.Period == Quarterly {
+		return 4
+	}
+
+	return 1
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProvider(t *testing.T) {
+	// Arrange / Act
+	mock, mockErr := NewProvider(context.Background(), config{provider: "mock"})
+	file, fileErr := NewProvider(context.Background(), config{provider: "file", dir: "./json/fixtures/yahoo-rapidapi/AAPL"})
+	_, noDirErr := NewProvider(context.Background(), config{provider: "file"})
+	_, unknownErr := NewProvider(context.Background(), config{provider: "bloomberg"})
+
+	// Assert
+	assert.NoError(t, mockErr)
+	assert.IsType(t, &YahooMockClient{}, mock)
+	assert.NoError(t, fileErr)
+	assert.IsType(t, &YahooFileClient{}, file)
+	assert.Error(t, noDirErr)
+	assert.Error(t, unknownErr)
+}
+
+func TestLoadFundamentals(t *testing.T) {
+	// Arrange
+	p := &YahooFileClient{Dir: "./json/fixtures/yahoo-rapidapi/AAPL"}
+
+	// Act
+	f, err := LoadFundamentals(p, "AAPL")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, f.Income.Len())
+	assert.Equal(t, 4, f.Balance.Len())
+	assert.Equal(t, 4, f.CashFlow.Len())
+	assert.NotZero(t, f.Stock.Root.SharesOutstanding)
+}
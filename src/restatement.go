@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RestatementOptions decide which restated line items are material
+type RestatementOptions struct {
+	// Material is the relative change of a line item from which it is a material restatement, e.g. 0.05
+	Material float64
+}
+
+// DefaultRestatementOptions treat a change of 5% or more as material
+func DefaultRestatementOptions() RestatementOptions {
+	return RestatementOptions{Material: 0.05}
+}
+
+// LineItemChange is a line item of a period reported differently by two fetches
+type LineItemChange struct {
+	Statement string
+	End       time.Time
+	Item      string
+	Old       Money
+	New       Money
+	// Change is (New - Old) / |Old|
+	Change decimal.Decimal
+	// NotMeaningful is set when Old is zero, so there is no Change
+	NotMeaningful bool
+	// Material is set when Change reaches RestatementOptions.Material, or a line item that was zero is now reported
+	Material bool
+}
+
+// Restatement is every line item the current fundamentals of a symbol report differently to the previous ones,
+// over the periods both report
+type Restatement struct {
+	Symbol  string
+	Changes []LineItemChange
+}
+
+// lineItem is a reported line item of a statement year
+type lineItem[T any] struct {
+	name  string
+	value func(T) Money
+}
+
+// incomeLineItems are the reported income statement line items, shares are left out as they may be today's
+var incomeLineItems = []lineItem[*YearIncomeStatement]{
+	{"Total Revenue", (*YearIncomeStatement).TotalRevenue},
+	{"Cost Of Revenue", (*YearIncomeStatement).CostOfRevenue},
+	{"Selling General Administrative", (*YearIncomeStatement).SellingGeneralAdministrative},
+	{"Research Development", (*YearIncomeStatement).ResearchDevelopment},
+	{"Interest Expense", (*YearIncomeStatement).InterestExpense},
+	{"Income Before Tax", (*YearIncomeStatement).IncomeBeforeTax},
+	{"Income Tax Expense", (*YearIncomeStatement).IncomeTaxExpense},
+	{"Net Income", (*YearIncomeStatement).NetEarnings},
+}
+
+// balanceLineItems are the reported balance sheet line items
+var balanceLineItems = []lineItem[*YearBalanceSheet]{
+	{"Cash", (*YearBalanceSheet).Cash},
+	{"Short Term Investments", (*YearBalanceSheet).ShortTermInvestments},
+	{"Net Receivables", (*YearBalanceSheet).NetReceivables},
+	{"Inventory", (*YearBalanceSheet).Inventory},
+	{"Other Current Assets", (*YearBalanceSheet).OtherCurrentAssets},
+	{"Total Current Assets", (*YearBalanceSheet).TotalCurrentAssets},
+	{"Long Term Investments", (*YearBalanceSheet).LongTermInvestments},
+	{"Property Plant Equipment", (*YearBalanceSheet).PropertyPlantEquipment},
+	{"Good Will", (*YearBalanceSheet).GoodWill},
+	{"Intangible Assets", (*YearBalanceSheet).IntangibleAssets},
+	{"Other Assets", (*YearBalanceSheet).OtherAssets},
+	{"Deferred Long Term Asset Charges", (*YearBalanceSheet).DeferredLongTermAssetCharges},
+	{"Total Assets", (*YearBalanceSheet).TotalAssets},
+	{"Accounts Payable", (*YearBalanceSheet).AccountsPayable},
+	{"Short Long Term Debt", (*YearBalanceSheet).ShortTermDebt},
+	{"Other Current Liab", (*YearBalanceSheet).OtherCurrentLiabilities},
+	{"Total Current Liabilities", (*YearBalanceSheet).TotalCurrentLiabilities},
+	{"Long Term Debt", (*YearBalanceSheet).LongTermDebt},
+	{"Deferred Long Term Liab", (*YearBalanceSheet).DeferredLongTermLiabilities},
+	{"Minority Interest", (*YearBalanceSheet).MinorityInterest},
+	{"Other Liab", (*YearBalanceSheet).OtherLiabilities},
+	{"Total Liab", (*YearBalanceSheet).TotalLiabilities},
+	{"Preferred Stock", (*YearBalanceSheet).PreferredStock},
+	{"Common Stock", (*YearBalanceSheet).CommonStock},
+	{"Capital Surplus", (*YearBalanceSheet).CapitalSurplus},
+	{"Retained Earnings", (*YearBalanceSheet).RetainedEarnings},
+	{"Treasury Stock", (*YearBalanceSheet).TreasuryStock},
+	{"Other Stockholder Equity", (*YearBalanceSheet).OtherStockholderEquity},
+	{"Total Stockholder Equity", (*YearBalanceSheet).TotalShareholdersEquity},
+	{"Net Tangible Assets", (*YearBalanceSheet).ReportedNetTangibleAssets},
+}
+
+// DiffIncomeStatements returns every line item current reports differently to previous
+func DiffIncomeStatements(previous, current *IncomeStatement, o RestatementOptions) []LineItemChange {
+	if previous == nil || current == nil {
+		return nil
+	}
+
+	return diffSeries("income", &previous.Series, &current.Series, incomeLineItems, o)
+}
+
+// DiffBalanceSheets returns every line item current reports differently to previous
+func DiffBalanceSheets(previous, current *BalanceSheet, o RestatementOptions) []LineItemChange {
+	if previous == nil || current == nil {
+		return nil
+	}
+
+	return diffSeries("balance", &previous.Series, &current.Series, balanceLineItems, o)
+}
+
+// diffSeries compares the line items of every period in both series. Periods only one of them reports are new
+// or have rolled off, neither is a restatement
+func diffSeries[T any](statement string, previous, current *Series[T], items []lineItem[T], o RestatementOptions) []LineItemChange {
+	x := []LineItemChange{}
+
+	for _, end := range current.Ends() {
+		old, ok := previous.Get(end)
+
+		if !ok {
+			continue
+		}
+
+		now := current.At(end)
+
+		for _, item := range items {
+			if a, b := item.value(old), item.value(now); !a.Amount.Equal(b.Amount) {
+				x = append(x, newLineItemChange(statement, end, item.name, a, b, o))
+			}
+		}
+	}
+
+	return x
+}
+
+func newLineItemChange(statement string, end time.Time, item string, was, now Money, o RestatementOptions) LineItemChange {
+	c := LineItemChange{Statement: statement, End: end, Item: item, Old: was, New: now}
+
+	if was.Amount.IsZero() {
+		c.NotMeaningful, c.Material = true, true
+		return c
+	}
+
+	c.Change = now.Amount.Sub(was.Amount).Div(was.Amount.Abs())
+	c.Material = c.Change.Abs().GreaterThanOrEqual(decimal.NewFromFloat(o.Material))
+
+	return c
+}
+
+// NewRestatement compares the income statements and balance sheets of current against previous
+func NewRestatement(previous, current *Fundamentals, o RestatementOptions) *Restatement {
+	r := &Restatement{Symbol: current.Symbol}
+
+	r.Changes = append(r.Changes, DiffIncomeStatements(previous.Income, current.Income, o)...)
+	r.Changes = append(r.Changes, DiffBalanceSheets(previous.Balance, current.Balance, o)...)
+
+	return r
+}
+
+// Restatement compares f, fetched from provider, against the fundamentals of its symbol stored from provider as
+// they were at before, nil when the store had none
+func (s *Store) Restatement(provider string, f *Fundamentals, before time.Time, o RestatementOptions) (*Restatement, error) {
+	previous, err := LoadFundamentalsFor(&StoreProvider{Store: s, Provider: provider, AsOf: before}, f.Symbol, f.Period)
+
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("restatement: %w", err)
+	}
+
+	return NewRestatement(previous, f, o), nil
+}
+
+// Material returns the material changes
+func (r *Restatement) Material() []LineItemChange {
+	x := []LineItemChange{}
+
+	for _, c := range r.Changes {
+		if c.Material {
+			x = append(x, c)
+		}
+	}
+
+	return x
+}
+
+// Print writes every changed line item as a table, followed by a flag when any change is material
+func (r *Restatement) Print(w io.Writer) {
+	if len(r.Changes) == 0 {
+		fmt.Fprintf(w, "No restatements of %s since the previous fetch\n", r.Symbol)
+		return
+	}
+
+	ends := []time.Time{}
+	for _, c := range r.Changes {
+		ends = append(ends, c.End)
+	}
+
+	label := periodLabels(ends)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Year\tStatement\tLine item\tOld\tNew\tChange\tMaterial")
+
+	for _, c := range r.Changes {
+		change := c.Change.Mul(decimal.New(100, 0)).StringFixed(2) + "%"
+
+		if c.NotMeaningful {
+			change = "n/m"
+		}
+
+		material := ""
+		if c.Material {
+			material = "MATERIAL"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", label(c.End), c.Statement, c.Item, c.Old.Amount, c.New.Amount, change, material)
+	}
+
+	tw.Flush()
+
+	if n := len(r.Material()); n > 0 {
+		fmt.Fprintf(w, "RESTATED %s: %d material of %d changed line items since the previous fetch\n", r.Symbol, n, len(r.Changes))
+	} else {
+		fmt.Fprintf(w, "Restated %s: %d changed line items since the previous fetch, none material\n", r.Symbol, len(r.Changes))
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// restatedIncome returns the bundled Apple income statement with the revenue and interest expense of its first
+// year restated
+func restatedIncome(t *testing.T, revenue, interest float64) *YahooIncomeStatementV15 {
+	y, err := (&YahooMockClient{}).GetIncomeStatement("AAPL")
+	assert.NoError(t, err)
+
+	h := &y.Root.IncomeStatementHistory[0]
+	h.TotalRevenue.Raw = int64(float64(h.TotalRevenue.Raw) * revenue)
+	h.InterestExpense.Raw = int64(float64(h.InterestExpense.Raw) * interest)
+
+	return y
+}
+
+func TestDiffIncomeStatements(t *testing.T) {
+	// Arrange
+	previous := NewIncomeStatement(restatedIncome(t, 1, 1), nil)
+	current := NewIncomeStatement(restatedIncome(t, 1.1, 1.01), nil)
+
+	// Act
+	x := DiffIncomeStatements(previous, current, DefaultRestatementOptions())
+
+	// Assert
+	assert.Len(t, x, 2)
+	assert.Equal(t, "Total Revenue", x[0].Item)
+	assert.Equal(t, previous.At(x[0].End).TotalRevenue(), x[0].Old)
+	assert.Equal(t, current.At(x[0].End).TotalRevenue(), x[0].New)
+	assert.Equal(t, "0.1000", x[0].Change.StringFixed(4))
+	assert.True(t, x[0].Material)
+	assert.Equal(t, "Interest Expense", x[1].Item)
+	assert.False(t, x[1].Material)
+}
+
+func TestDiffBalanceSheets(t *testing.T) {
+	// Arrange
+	end := time.Date(2021, 9, 25, 0, 0, 0, 0, time.UTC)
+	gone := time.Date(2017, 9, 30, 0, 0, 0, 0, time.UTC)
+	balance := func(cash, goodWill int64, ends ...time.Time) *BalanceSheet {
+		y := &YahooBalanceSheetV1{}
+
+		for _, e := range ends {
+			y.Root = append(y.Root,
+				YahooBalanceItem{Name: "Cash", Values: map[time.Time]int64{e: cash}},
+				YahooBalanceItem{Name: "Good Will", Values: map[time.Time]int64{e: goodWill}},
+			)
+		}
+
+		return NewBalanceSheet(y)
+	}
+
+	// Act
+	x := DiffBalanceSheets(balance(100, 0, gone, end), balance(96, 5, end), DefaultRestatementOptions())
+
+	// Assert
+	assert.Len(t, x, 2)
+	assert.Equal(t, "Cash", x[0].Item)
+	assert.True(t, x[0].Change.Equal(decimal.New(-4, -2)))
+	assert.False(t, x[0].Material)
+	assert.Equal(t, "Good Will", x[1].Item)
+	assert.True(t, x[1].NotMeaningful)
+	assert.True(t, x[1].Material)
+}
+
+func TestStoreRestatement(t *testing.T) {
+	// Arrange
+	s := openTestStore(t)
+	first := time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return first }
+	LoadFundamentals(&StoringProvider{Provider: &YahooMockClient{}, Name: "mock", Store: s}, "AAPL")
+
+	second := first.Add(24 * time.Hour)
+	s.now = func() time.Time { return second }
+	assert.NoError(t, s.SaveIncomeStatement("mock", "AAPL", restatedIncome(t, 0.9, 1)))
+	f, _ := LoadFundamentals(&StoreProvider{Store: s, Provider: "mock"}, "AAPL")
+
+	// Act
+	r, err := s.Restatement("mock", f, second.Add(-time.Nanosecond), DefaultRestatementOptions())
+	none, noneErr := s.Restatement("mock", f, first.Add(-time.Nanosecond), DefaultRestatementOptions())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, r.Changes, 1)
+	assert.Len(t, r.Material(), 1)
+	assert.Equal(t, "-0.1000", r.Changes[0].Change.StringFixed(4))
+	assert.NoError(t, noneErr)
+	assert.Nil(t, none)
+}
+
+func TestStoreRestatementOfTheSameProvider(t *testing.T) {
+	// Arrange
+	s := openTestStore(t)
+	first := time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return first }
+	LoadFundamentals(&StoringProvider{Provider: &YahooMockClient{}, Name: "mock", Store: s}, "AAPL")
+
+	second := first.Add(24 * time.Hour)
+	s.now = func() time.Time { return second }
+	assert.NoError(t, s.SaveIncomeStatement("other", "AAPL", restatedIncome(t, 0.9, 1)))
+
+	third := second.Add(24 * time.Hour)
+	s.now = func() time.Time { return third }
+	f, _ := LoadFundamentals(&StoringProvider{Provider: &YahooMockClient{}, Name: "mock", Store: s}, "AAPL")
+
+	// Act
+	r, err := s.Restatement("mock", f, third.Add(-time.Nanosecond), DefaultRestatementOptions())
+	none, noneErr := s.Restatement("other", f, second.Add(-time.Nanosecond), DefaultRestatementOptions())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, r.Changes, "the other provider's later figures aren't a restatement of mock's")
+	assert.NoError(t, noneErr)
+	assert.Nil(t, none, "mock's fetch isn't a previous snapshot of the other provider")
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RetainedEarnings follows the earnings a business keeps, and what it hands back through buybacks.
+// Rising retained earnings and a falling share count suggest a durable competitive advantage
+type RetainedEarnings struct {
+	Series[*YearRetainedEarnings]
+	// Trend of retained earnings, rated by the profile's trend rules
+	Trend Trend
+	// BuybacksToNetEarnings is the net cash spent on buybacks over the net earnings of every loaded year
+	BuybacksToNetEarnings float64
+	// BuybacksNotMeaningful is set when there were no net earnings to compare buybacks with, and is rated BAD
+	BuybacksNotMeaningful       bool
+	BuybacksToNetEarningsRating Rating
+	// ShareCountChange is the compound annual change in shares outstanding, negative when shares are retired
+	ShareCountChange float64
+	// ShareCountNotMeaningful is set when fewer than two years have their own share count, today's shares
+	// outstanding standing in for every year can't change. ShareCountChangeRating is then left unrated
+	ShareCountNotMeaningful bool
+	ShareCountChangeRating  Rating
+}
+
+// YearRetainedEarnings reconciles the change in retained earnings with net earnings less dividends
+type YearRetainedEarnings struct {
+	Year             int
+	End              time.Time
+	RetainedEarnings Money
+	TreasuryStock    Money
+	// Change in retained earnings on the year before, zero for the first loaded year
+	Change      Money
+	NetEarnings Money
+	// DividendsPaid is positive, the cash handed to shareholders
+	DividendsPaid Money
+	// Unexplained is Change - (NetEarnings - DividendsPaid), mostly buybacks charged to retained earnings
+	Unexplained Money
+	// Buybacks is the net cash spent repurchasing stock
+	Buybacks          Money
+	SharesOutstanding int64
+}
+
+// NewRetainedEarnings tracks retained earnings and buybacks for every year with a balance sheet, nil uses the default profile
+func NewRetainedEarnings(f *Fundamentals, profile *RatingProfile) (*RetainedEarnings, error) {
+	if profile == nil {
+		profile = DefaultRatingProfile()
+	}
+
+	if f.Balance == nil || f.Balance.Len() == 0 {
+		return nil, fmt.Errorf("retained earnings: no balance sheets: %w", ErrNotEnoughData)
+	}
+
+	r := &RetainedEarnings{}
+	retained := []float64{}
+	shares := []float64{}
+	shareEnds := []time.Time{}
+
+	buybacks := []Money{}
+	netEarnings := []Money{}
+
+	for _, p := range f.Balance.Periods() {
+		b := p.Value
+		zero := b.money(decimal.Zero)
+
+		y := &YearRetainedEarnings{
+			Year:             b.Year,
+			End:              b.End,
+			RetainedEarnings: b.RetainedEarnings(),
+			TreasuryStock:    b.TreasuryStock(),
+			Change:           zero,
+			NetEarnings:      zero,
+			DividendsPaid:    zero,
+			Unexplained:      zero,
+			Buybacks:         zero,
+		}
+
+		if c := f.CashFlow.At(b.End); c != nil {
+			y.NetEarnings = c.NetEarnings()
+			y.DividendsPaid = c.DividendsPaid().Neg()
+			y.Buybacks = c.Buybacks()
+		}
+
+		if i := f.Income.At(b.End); i != nil {
+			y.NetEarnings = i.NetEarnings()
+			y.SharesOutstanding = i.SharesOutstanding()
+
+			if i.HasOwnShares() {
+				shares = append(shares, float64(y.SharesOutstanding))
+				shareEnds = append(shareEnds, b.End)
+			}
+		}
+
+		if opening, ok := f.Balance.Previous(p.End); ok {
+			var err error
+
+			if y.Change, err = y.RetainedEarnings.Sub(opening.RetainedEarnings()); err != nil {
+				return nil, fmt.Errorf("retained earnings: %d change: %w", y.Year, err)
+			}
+
+			if y.Unexplained, err = Sum(y.Change, y.NetEarnings.Neg(), y.DividendsPaid); err != nil {
+				return nil, fmt.Errorf("retained earnings: %d unexplained: %w", y.Year, err)
+			}
+		}
+
+		buybacks = append(buybacks, y.Buybacks)
+		netEarnings = append(netEarnings, y.NetEarnings)
+		retained = append(retained, y.RetainedEarnings.Float64())
+
+		r.Set(p.End, y)
+	}
+
+	r.Trend = NewTrend(retained, profile.Trend)
+
+	totalBuybacks, err := Sum(buybacks...)
+
+	if err != nil {
+		return nil, fmt.Errorf("retained earnings: buybacks: %w", err)
+	}
+
+	totalNetEarnings, err := Sum(netEarnings...)
+
+	if err != nil {
+		return nil, fmt.Errorf("retained earnings: net earnings: %w", err)
+	}
+
+	r.BuybacksToNetEarnings, err = ratioFloat(Ratio(totalBuybacks, totalNetEarnings))
+
+	switch {
+	case errors.Is(err, ErrNotMeaningful):
+		r.BuybacksNotMeaningful = true
+		r.BuybacksToNetEarningsRating = BAD
+	case err != nil:
+		return nil, fmt.Errorf("retained earnings: buybacks to net earnings: %w", err)
+	default:
+		r.BuybacksToNetEarningsRating, _ = profile.Rate("buybacksToNetEarnings", r.BuybacksToNetEarnings)
+	}
+
+	if len(shares) < 2 {
+		r.ShareCountNotMeaningful = true
+		return r, nil
+	}
+
+	r.ShareCountChange = newTrend(shares, shareEnds, profile.Trend).CAGR
+
+	if math.IsNaN(r.ShareCountChange) {
+		return nil, fmt.Errorf("retained earnings: no shares outstanding: %w", ErrNotEnoughData)
+	}
+
+	r.ShareCountChangeRating, _ = profile.Rate("shareCountChange", r.ShareCountChange)
+
+	return r, nil
+}
+
+// Print writes the retained earnings reconciliation, and how buybacks rate
+func (R *RetainedEarnings) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	label := periodLabels(R.Ends())
+
+	fmt.Fprintln(tw, "Year\tRetained Earnings\tChange\tNet Earnings\tDividends\tUnexplained\tBuybacks\tTreasury Stock\tShares")
+
+	for _, y := range R.Values() {
+		change, unexplained := y.Change.Amount.String(), y.Unexplained.Amount.String()
+
+		// The first year has no opening balance to change from
+		if _, ok := R.Previous(y.End); !ok {
+			change, unexplained = "", ""
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n", label(y.End), y.RetainedEarnings.Amount, change, y.NetEarnings.Amount,
+			y.DividendsPaid.Amount, unexplained, y.Buybacks.Amount, y.TreasuryStock.Amount, y.SharesOutstanding)
+	}
+
+	tw.Flush()
+
+	fmt.Fprintf(w, "Retained earnings trend %s, %d down years\n", R.Trend.Rating, R.Trend.DownYears)
+	if R.BuybacksNotMeaningful {
+		fmt.Fprintf(w, "Buybacks n/m of net earnings %s, not meaningful\n", R.BuybacksToNetEarningsRating)
+	} else {
+		fmt.Fprintf(w, "Buybacks %.2f%% of net earnings %s\n", R.BuybacksToNetEarnings*100, R.BuybacksToNetEarningsRating)
+	}
+	if R.ShareCountNotMeaningful {
+		fmt.Fprintln(w, "Share count n/m a year, not rated: no share counts per year")
+	} else {
+		fmt.Fprintf(w, "Share count %+.2f%% a year %s\n", R.ShareCountChange*100, R.ShareCountChangeRating)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetainedEarningsReconciliation(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+
+	var b bytes.Buffer
+
+	// Act
+	r, err := NewRetainedEarnings(f, nil)
+	r.Print(&b)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, r.Len())
+
+	lines := strings.Split(b.String(), "\n")
+	assert.Len(t, strings.Fields(lines[1]), len(strings.Fields(lines[2]))-2, "the first year's change and unexplained are blank")
+
+	y := r.Year(2021)
+	assert.Equal(t, int64(5562000000-14966000000), y.Change.Units())
+	assert.Equal(t, int64(14467000000), y.DividendsPaid.Units())
+	assert.Equal(t, y.Change.Units()-(y.NetEarnings.Units()-y.DividendsPaid.Units()), y.Unexplained.Units())
+	assert.True(t, r.Year(2018).Change.Amount.IsZero(), "no opening balance for the first year")
+}
+
+func TestRetainedEarningsRatings(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+
+	var b bytes.Buffer
+
+	// Act
+	r, _ := NewRetainedEarnings(f, nil)
+	r.Print(&b)
+
+	// Assert
+	assert.Equal(t, BAD, r.Trend.Rating, "buybacks shrank retained earnings every year")
+	assert.Equal(t, 3, r.Trend.DownYears)
+	assert.InDelta(t, 1.1618, r.BuybacksToNetEarnings, 0.0001)
+	assert.Equal(t, GOOD, r.BuybacksToNetEarningsRating)
+	assert.True(t, r.ShareCountNotMeaningful, "the mock only has today's shares outstanding")
+	assert.Contains(t, b.String(), "Share count n/m a year, not rated")
+	assert.NotContains(t, b.String(), "+0.00%")
+}
+
+func TestRetainedEarningsBuybacksWithoutNetEarnings(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	for _, y := range f.Income.Values() {
+		y.netEarnings = decimal.Zero
+	}
+	var b bytes.Buffer
+
+	// Act
+	r, err := NewRetainedEarnings(f, nil)
+	r.Print(&b)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, r.BuybacksNotMeaningful)
+	assert.Equal(t, BAD, r.BuybacksToNetEarningsRating)
+	assert.Contains(t, b.String(), "Buybacks n/m of net earnings BAD")
+}
+
+func TestRetainedEarningsShareCountChange(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&CSVFileClient{Dir: "./json/csv"}, "ACME")
+
+	// Act
+	r, err := NewRetainedEarnings(f, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1080000), r.Year(2019).SharesOutstanding)
+	assert.Equal(t, int64(1000000), r.Year(2021).SharesOutstanding)
+	assert.InDelta(t, math.Pow(1000000.0/1080000, 1.0/2)-1, r.ShareCountChange, 1e-9)
+	assert.Less(t, r.ShareCountChange, -0.01)
+	assert.Equal(t, GOOD, r.ShareCountChangeRating)
+}
+
+func TestRetainedEarningsWithoutBalanceSheets(t *testing.T) {
+	// Arrange
+	f := &Fundamentals{Income: &IncomeStatement{}, Balance: &BalanceSheet{}, CashFlow: &CashFlowStatement{}}
+
+	// Act
+	_, err := NewRetainedEarnings(f, nil)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotEnoughData)
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Score is the composite value rating of a business over every loaded year
+type Score struct {
+	Total        float64
+	PassScore    float64
+	Passed       bool
+	Explanations []Explanation
+}
+
+// Explanation records how one metric in one year contributed to the Score
+type Explanation struct {
+	Year   int
+	End    time.Time
+	Metric string
+	Value  float64
+	// NotMeaningful is set when the value can't be calculated, e.g. a margin of zero revenue, and is rated BAD
+	NotMeaningful bool
+	Band          string
+	Rating        Rating
+	Weight        float64
+	Contribution  float64
+}
+
+// valueMetric is one ValueRating method, and how to explain the value behind it
+type valueMetric struct {
+	name string
+	// needsIncome / needsBalance say which statements must be loaded for the year
+	needsIncome  bool
+	needsBalance bool
+	value        func(v *ValueRating) (decimal.Decimal, error)
+	rate         func(v *ValueRating) Rating
+	// band describes the rule when the metric isn't banded in the profile
+	band string
+}
+
+// valueMetrics are every ValueRating method that takes part in the composite score
+var valueMetrics = []valueMetric{
+	{
+		name:        "grossProfitMargin",
+		needsIncome: true,
+		value:       func(v *ValueRating) (decimal.Decimal, error) { return v.income.GrossProfitMargin() },
+		rate:        (*ValueRating).GrossProfit,
+	},
+	{
+		name:        "sellingGeneralAdministrativeMargin",
+		needsIncome: true,
+		value:       func(v *ValueRating) (decimal.Decimal, error) { return v.income.SellingGeneralAdministrativeMargin() },
+		rate:        (*ValueRating).SellingGeneralAdministrativeMargin,
+	},
+	{
+		name:        "interestExpenseMargin",
+		needsIncome: true,
+		value:       func(v *ValueRating) (decimal.Decimal, error) { return v.income.InterestExpenseMargin() },
+		rate:        (*ValueRating).InterestExpenseMargin,
+	},
+	{
+		name:        "researchDevelopmentMargin",
+		needsIncome: true,
+		value:       func(v *ValueRating) (decimal.Decimal, error) { return v.income.ResearchDevelopmentMargin() },
+		rate:        (*ValueRating).ResearchDevelopmentMargin,
+	},
+	{
+		name:         "currentRatio",
+		needsBalance: true,
+		value:        func(v *ValueRating) (decimal.Decimal, error) { return v.balance.CurrentRatio() },
+		rate:         (*ValueRating).CurrentRatio,
+	},
+	{
+		name:         "debtToShareholderEquityRatio",
+		needsBalance: true,
+		value:        func(v *ValueRating) (decimal.Decimal, error) { return v.balance.DebtToShareholderEquityRatio() },
+		rate:         (*ValueRating).DebtToShareholderEquityRatio,
+	},
+	{
+		name:         "longTermDebtYearsToRepay",
+		needsIncome:  true,
+		needsBalance: true,
+		value:        (*ValueRating).longTermDebtYearsToRepay,
+		rate:         (*ValueRating).LongTermDebtYearsToRepay,
+	},
+	{
+		name:         "shortVsLongTermDebt",
+		needsBalance: true,
+		value:        func(v *ValueRating) (decimal.Decimal, error) { return v.balance.ShortToLongTermDebtRatio() },
+		rate:         (*ValueRating).ShortVsLongTermDebt,
+		band:         "GOOD when Short Term Debt < Long Term Debt",
+	},
+	{
+		name:         "returnOnShareholdersEquity",
+		needsIncome:  true,
+		needsBalance: true,
+		value:        (*ValueRating).returnOnShareholdersEquity,
+		rate:         (*ValueRating).ReturnOnShareholdersEquity,
+	},
+	{
+		name:         "returnOnTotalAssets",
+		needsIncome:  true,
+		needsBalance: true,
+		value:        (*ValueRating).returnOnTotalAssets,
+		rate:         (*ValueRating).ReturnOnTotalAssets,
+	},
+	{
+		name:         "leverage",
+		needsIncome:  true,
+		needsBalance: true,
+		value:        (*ValueRating).leverage,
+		rate:         (*ValueRating).Leverage,
+	},
+}
+
+func scoredMetric(name string) *valueMetric {
+	for i := range valueMetrics {
+		if valueMetrics[i].name == name {
+			return &valueMetrics[i]
+		}
+	}
+
+	return nil
+}
+
+// points converts a Rating into its share of a metric's weight
+func points(r Rating) float64 {
+	switch r {
+	case GOOD:
+		return 1
+	case OK:
+		return 0.5
+	}
+
+	return 0
+}
+
+// ScoreValue rates every ValueRating metric for every loaded year and combines them using the profile weights
+func ScoreValue(f *Fundamentals, profile *RatingProfile) *Score {
+	if profile == nil {
+		profile = DefaultRatingProfile()
+	}
+
+	s := &Score{PassScore: *profile.PassScore}
+
+	var weights float64 = 0
+	var earned float64 = 0
+
+	for _, end := range fiscalPeriods(f) {
+		v := NewValueRating(f.Income.At(end), f.Balance.At(end), profile).
+			WithOpeningBalance(f.Balance.Before(end)).
+			WithPeriodsPerYear(f.PeriodsPerYear())
+
+		for _, m := range valueMetrics {
+			if (m.needsIncome && v.income == nil) || (m.needsBalance && v.balance == nil) {
+				continue
+			}
+
+			e := Explanation{
+				Year:   end.Year(),
+				End:    end,
+				Metric: m.name,
+				Rating: m.rate(v),
+				Weight: profile.Weight(m.name),
+				Band:   m.band,
+			}
+
+			value, err := ratioFloat(m.value(v))
+
+			switch {
+			case err != nil:
+				e.NotMeaningful = true
+				e.Band = "not meaningful"
+			case e.Band == "":
+				_, band := profile.Rate(m.name, value)
+				e.Band = band.String()
+			}
+
+			e.Value = value
+
+			e.Contribution = e.Weight * points(e.Rating)
+
+			weights += e.Weight
+			earned += e.Contribution
+
+			s.Explanations = append(s.Explanations, e)
+		}
+	}
+
+	if weights > 0 {
+		s.Total = earned / weights
+
+		// Contributions become each metric's share of the total
+		for i := range s.Explanations {
+			s.Explanations[i].Contribution /= weights
+		}
+	}
+
+	s.Passed = len(s.Explanations) > 0 && s.Total >= s.PassScore
+
+	return s
+}
+
+// Print writes the score and its explanation trail as a table
+func (s *Score) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	ends := []time.Time{}
+	for _, e := range s.Explanations {
+		ends = append(ends, e.End)
+	}
+
+	label := periodLabels(ends)
+
+	fmt.Fprintln(tw, "Year\tMetric\tValue\tBand\tWeight\tContribution")
+
+	for _, e := range s.Explanations {
+		value := fmt.Sprintf("%.4f", e.Value)
+
+		if e.NotMeaningful {
+			value = "n/m"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.2f\t%.4f\n", label(e.End), e.Metric, value, e.Band, e.Weight, e.Contribution)
+	}
+
+	tw.Flush()
+
+	result := "FAIL"
+	if s.Passed {
+		result = "PASS"
+	}
+
+	fmt.Fprintf(w, "Score %.2f (pass %.2f) %s\n", s.Total, s.PassScore, result)
+}
+
+// fiscalPeriods returns the end of every period with an income statement or balance sheet, oldest first
+func fiscalPeriods(f *Fundamentals) []time.Time {
+	x := Series[bool]{}
+
+	for _, end := range f.Income.Ends() {
+		x.Set(end, true)
+	}
+
+	for _, end := range f.Balance.Ends() {
+		x.Set(end, true)
+	}
+
+	return x.Ends()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoreValue(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+
+	// Act
+	s := ScoreValue(f, nil)
+
+	// Assert
+	assert.Len(t, s.Explanations, 4*len(valueMetrics))
+	assert.InDelta(t, 0.8026, s.Total, 0.0001)
+	assert.True(t, s.Passed)
+
+	var sum float64 = 0
+	for _, e := range s.Explanations {
+		sum += e.Contribution
+	}
+	assert.InDelta(t, s.Total, sum, 1e-9)
+}
+
+func TestScoreValueWeights(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	p, _ := ParseRatingProfile([]byte(`
+passScore: 0.5
+weights:
+  grossProfitMargin: 0
+  sellingGeneralAdministrativeMargin: 0
+  interestExpenseMargin: 0
+  researchDevelopmentMargin: 0
+  currentRatio: 0
+  shortVsLongTermDebt: 0
+  longTermDebtYearsToRepay: 0
+  returnOnShareholdersEquity: 0
+  returnOnTotalAssets: 0
+  leverage: 0
+`), DefaultRatingProfile())
+
+	// Act
+	s := ScoreValue(f, p)
+
+	// Assert
+	assert.Zero(t, s.Total)
+	assert.False(t, s.Passed)
+}
+
+func TestScoreValueNotMeaningful(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	f.Balance.Year(2021).totalShareholdersEquity = decimal.Zero
+	var b bytes.Buffer
+
+	// Act
+	s := ScoreValue(f, nil)
+	s.Print(&b)
+
+	// Assert
+	for _, e := range s.Explanations {
+		if e.Year == 2021 && e.Metric == "debtToShareholderEquityRatio" {
+			assert.True(t, e.NotMeaningful)
+			assert.Equal(t, BAD, e.Rating)
+		}
+	}
+	assert.Contains(t, b.String(), "2021  debtToShareholderEquityRatio        n/m      not meaningful")
+}
+
+func TestScorePrint(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	var b bytes.Buffer
+
+	// Act
+	ScoreValue(f, nil).Print(&b)
+
+	// Assert
+	assert.Contains(t, b.String(), "2021  debtToShareholderEquityRatio")
+	assert.Contains(t, b.String(), "BAD (0.8, +inf)")
+	assert.Contains(t, b.String(), "PASS")
+}
+
+func TestScoreValueAnnualisesQuarters(t *testing.T) {
+	// Arrange
+	f, _ := LoadQuarterlyFundamentals(&YahooMockClient{}, "AAPL")
+	end, _ := f.Income.Latest()
+	d, _ := NewDuPont(end, f.Balance.At(end.End), f.Balance.Before(end.End))
+
+	// Act
+	s := ScoreValue(f, nil)
+
+	// Assert
+	for _, e := range s.Explanations {
+		if e.End.Equal(end.End) && e.Metric == "returnOnShareholdersEquity" {
+			roe, _ := d.ReturnOnShareholdersEquity.Float64()
+			assert.InDelta(t, 4*roe, e.Value, 1e-9)
+		}
+	}
+}
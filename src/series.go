@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Series is an ordered collection of statements keyed by fiscal year end, oldest first
+type Series[T any] struct {
+	periods []Period[T]
+}
+
+// Period pairs a statement with the fiscal year end it reports on
+type Period[T any] struct {
+	End   time.Time
+	Value T
+}
+
+// Set adds the value for the fiscal year ending at end, replacing any existing entry
+func (s *Series[T]) Set(end time.Time, value T) {
+	i := sort.Search(len(s.periods), func(i int) bool {
+		return !s.periods[i].End.Before(end)
+	})
+
+	if i < len(s.periods) && s.periods[i].End.Equal(end) {
+		s.periods[i].Value = value
+		return
+	}
+
+	s.periods = append(s.periods, Period[T]{})
+	copy(s.periods[i+1:], s.periods[i:])
+	s.periods[i] = Period[T]{End: end, Value: value}
+}
+
+// Get returns the value for the fiscal year ending at end
+func (s *Series[T]) Get(end time.Time) (T, bool) {
+	for _, p := range s.periods {
+		if p.End.Equal(end) {
+			return p.Value, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// Year returns the value for the fiscal year ending in the given calendar year, or the zero value
+func (s *Series[T]) Year(year int) T {
+	for _, p := range s.periods {
+		if p.End.Year() == year {
+			return p.Value
+		}
+	}
+
+	var zero T
+	return zero
+}
+
+// At returns the value for the period ending at end, or the zero value
+func (s *Series[T]) At(end time.Time) T {
+	v, _ := s.Get(end)
+
+	return v
+}
+
+// Before returns the value of the latest period ending before end, or the zero value
+func (s *Series[T]) Before(end time.Time) T {
+	for i := len(s.periods) - 1; i >= 0; i-- {
+		if s.periods[i].End.Before(end) {
+			return s.periods[i].Value
+		}
+	}
+
+	var zero T
+	return zero
+}
+
+// Len returns the number of fiscal years loaded
+func (s *Series[T]) Len() int {
+	return len(s.periods)
+}
+
+// Periods returns every fiscal year, oldest first
+func (s *Series[T]) Periods() []Period[T] {
+	return s.periods
+}
+
+// Values returns every statement, oldest first
+func (s *Series[T]) Values() []T {
+	x := make([]T, len(s.periods))
+
+	for i, p := range s.periods {
+		x[i] = p.Value
+	}
+
+	return x
+}
+
+// Ends returns every fiscal year end, oldest first
+func (s *Series[T]) Ends() []time.Time {
+	x := make([]time.Time, len(s.periods))
+
+	for i, p := range s.periods {
+		x[i] = p.End
+	}
+
+	return x
+}
+
+// Latest returns the most recent fiscal year
+func (s *Series[T]) Latest() (T, bool) {
+	if len(s.periods) == 0 {
+		var zero T
+		return zero, false
+	}
+
+	return s.periods[len(s.periods)-1].Value, true
+}
+
+// Previous returns the fiscal year before the one ending at end
+func (s *Series[T]) Previous(end time.Time) (T, bool) {
+	for i, p := range s.periods {
+		if p.End.Equal(end) && i > 0 {
+			return s.periods[i-1].Value, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// periodLabels names each period by its fiscal year, or by its end date when several periods end in the
+// same year, e.g. quarters
+func periodLabels(ends []time.Time) func(end time.Time) string {
+	years := map[int]time.Time{}
+	dates := false
+
+	for _, end := range ends {
+		if seen, ok := years[end.Year()]; ok && !seen.Equal(end) {
+			dates = true
+		}
+
+		years[end.Year()] = end
+	}
+
+	return func(end time.Time) string {
+		if dates {
+			return end.Format("2006-01-02")
+		}
+
+		return fmt.Sprint(end.Year())
+	}
+}
+
+// mean returns the arithmetic mean of x
+func mean(x []float64) float64 {
+	if len(x) == 0 {
+		return 0
+	}
+
+	var sum float64 = 0
+	for _, e := range x {
+		sum += e
+	}
+
+	return sum / float64(len(x))
+}
+
+// sampleVariance returns the sample variance of x (n - 1 degrees of freedom)
+func sampleVariance(x []float64) float64 {
+	if len(x) < 2 {
+		return 0
+	}
+
+	m := mean(x)
+
+	var sum float64 = 0
+	for _, e := range x {
+		sum += (e - m) * (e - m)
+	}
+
+	return sum / float64(len(x)-1)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeriesSetKeepsFiscalYearsOrdered(t *testing.T) {
+	// Arrange
+	s := Series[int]{}
+
+	// Act
+	s.Set(time.Date(2021, 9, 25, 0, 0, 0, 0, time.UTC), 2021)
+	s.Set(time.Date(2018, 9, 29, 0, 0, 0, 0, time.UTC), 2018)
+	s.Set(time.Date(2022, 9, 24, 0, 0, 0, 0, time.UTC), 2022)
+	s.Set(time.Date(2018, 9, 29, 0, 0, 0, 0, time.UTC), 2018)
+
+	// Assert
+	assert.Equal(t, []int{2018, 2021, 2022}, s.Values())
+	assert.Equal(t, 2021, s.Year(2021))
+	assert.Zero(t, s.Year(2019))
+
+	latest, ok := s.Latest()
+	assert.True(t, ok)
+	assert.Equal(t, 2022, latest)
+
+	prev, ok := s.Previous(time.Date(2022, 9, 24, 0, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, 2021, prev)
+}
+
+func TestSeriesAtAndBefore(t *testing.T) {
+	// Arrange
+	s := Series[int]{}
+	s.Set(time.Date(2021, 9, 25, 0, 0, 0, 0, time.UTC), 2021)
+	s.Set(time.Date(2019, 9, 28, 0, 0, 0, 0, time.UTC), 2019)
+
+	// Act / Assert
+	assert.Equal(t, 2021, s.At(time.Date(2021, 9, 25, 0, 0, 0, 0, time.UTC)))
+	assert.Zero(t, s.At(time.Date(2020, 9, 26, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 2019, s.Before(time.Date(2021, 9, 25, 0, 0, 0, 0, time.UTC)), "skips the missing year")
+	assert.Zero(t, s.Before(time.Date(2019, 9, 28, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestPeriodLabels(t *testing.T) {
+	// Arrange
+	years := []time.Time{time.Date(2020, 9, 26, 0, 0, 0, 0, time.UTC), time.Date(2021, 9, 25, 0, 0, 0, 0, time.UTC)}
+	quarters := []time.Time{time.Date(2021, 9, 25, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 25, 0, 0, 0, 0, time.UTC)}
+
+	// Act
+	year := periodLabels(append(years, years...))
+	quarter := periodLabels(quarters)
+
+	// Assert
+	assert.Equal(t, "2021", year(years[1]))
+	assert.Equal(t, "2021-12-25", quarter(quarters[1]))
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// Action is what a Signal recommends doing with the stock
+type Action string
+
+const (
+	BUY  Action = "BUY"
+	HOLD Action = "HOLD"
+	SELL Action = "SELL"
+)
+
+// SignalRules decide when a stock is a BUY or a SELL
+type SignalRules struct {
+	Buy  BuyRules  `yaml:"buy"`
+	Sell SellRules `yaml:"sell"`
+}
+
+// BuyRules must all hold for a BUY
+type BuyRules struct {
+	MinMarginOfSafety              float64 `yaml:"minMarginOfSafety"`
+	MinScore                       float64 `yaml:"minScore"`
+	MaxPriceToTwoHundredDayAverage float64 `yaml:"maxPriceToTwoHundredDayAverage"`
+	MaxAboveFiftyTwoWeekLow        float64 `yaml:"maxAboveFiftyTwoWeekLow"`
+	MinBelowFiftyTwoWeekHigh       float64 `yaml:"minBelowFiftyTwoWeekHigh"`
+}
+
+// SellRules trigger a SELL when any holds
+type SellRules struct {
+	MaxMarginOfSafety              float64 `yaml:"maxMarginOfSafety"`
+	MinScore                       float64 `yaml:"minScore"`
+	MinPriceToTwoHundredDayAverage float64 `yaml:"minPriceToTwoHundredDayAverage"`
+}
+
+// Signal is the recommended Action and the rules behind it
+type Signal struct {
+	Action  Action
+	Reasons []Reason
+}
+
+// Reason is one rule, and whether it held
+type Reason struct {
+	Signal Action
+	Rule   string
+	Held   bool
+	Detail string
+}
+
+// NewSignal combines the valuation, composite score and price of a stock into a BUY, HOLD or SELL.
+// valuation may be nil when the business couldn't be valued
+func NewSignal(v *Valuation, s *Score, stock *YahooStockInfo, rules *SignalRules) *Signal {
+	if rules == nil {
+		rules = DefaultRatingProfile().Signals
+	}
+
+	marginOfSafety := math.NaN()
+	if v != nil {
+		marginOfSafety = v.MarginOfSafety
+	}
+
+	var price, average, low, high float64
+	if stock != nil {
+		price, average = stock.Root.CurrentPrice, stock.Root.TwoHundredDayAverage
+		low, high = stock.Root.FiftyTwoWeekLow, stock.Root.FiftyTwoWeekHigh
+	}
+
+	// NaN fails every comparison, so missing prices never trigger a rule
+	toAverage := math.NaN()
+	if price > 0 && average > 0 {
+		toAverage = price / average
+	}
+
+	aboveLow := math.NaN()
+	if price > 0 && low > 0 {
+		aboveLow = price/low - 1
+	}
+
+	belowHigh := math.NaN()
+	if price > 0 && high > 0 {
+		belowHigh = 1 - price/high
+	}
+
+	sig := &Signal{}
+
+	add := func(signal Action, rule string, held bool, value float64) bool {
+		detail := fmt.Sprintf("%.4f", value)
+
+		if math.IsNaN(value) {
+			detail = "not available"
+		}
+
+		sig.Reasons = append(sig.Reasons, Reason{Signal: signal, Rule: rule, Held: held, Detail: detail})
+
+		return held
+	}
+
+	buy := add(BUY, fmt.Sprintf("margin of safety >= %.2f", rules.Buy.MinMarginOfSafety), marginOfSafety >= rules.Buy.MinMarginOfSafety, marginOfSafety)
+	buy = add(BUY, fmt.Sprintf("score >= %.2f", rules.Buy.MinScore), s.Total >= rules.Buy.MinScore, s.Total) && buy
+	buy = add(BUY, fmt.Sprintf("price / 200 day average <= %.2f", rules.Buy.MaxPriceToTwoHundredDayAverage), toAverage <= rules.Buy.MaxPriceToTwoHundredDayAverage, toAverage) && buy
+	buy = add(BUY, fmt.Sprintf("price above 52 week low <= %.2f", rules.Buy.MaxAboveFiftyTwoWeekLow), aboveLow <= rules.Buy.MaxAboveFiftyTwoWeekLow, aboveLow) && buy
+	buy = add(BUY, fmt.Sprintf("price below 52 week high >= %.2f", rules.Buy.MinBelowFiftyTwoWeekHigh), belowHigh >= rules.Buy.MinBelowFiftyTwoWeekHigh, belowHigh) && buy
+
+	sell := add(SELL, fmt.Sprintf("margin of safety <= %.2f", rules.Sell.MaxMarginOfSafety), marginOfSafety <= rules.Sell.MaxMarginOfSafety, marginOfSafety)
+	sell = add(SELL, fmt.Sprintf("score < %.2f", rules.Sell.MinScore), s.Total < rules.Sell.MinScore, s.Total) || sell
+	sell = add(SELL, fmt.Sprintf("price / 200 day average >= %.2f", rules.Sell.MinPriceToTwoHundredDayAverage), toAverage >= rules.Sell.MinPriceToTwoHundredDayAverage, toAverage) || sell
+
+	switch {
+	case sell:
+		sig.Action = SELL
+	case buy:
+		sig.Action = BUY
+	default:
+		sig.Action = HOLD
+	}
+
+	return sig
+}
+
+// Print writes the signal and every rule behind it
+func (s *Signal) Print(w io.Writer) {
+	fmt.Fprintln(w, "Signal", s.Action)
+
+	for _, r := range s.Reasons {
+		held := "does not hold"
+		if r.Held {
+			held = "holds"
+		}
+
+		fmt.Fprintf(w, "  %s rule %s %s (%s)\n", r.Signal, r.Rule, held, r.Detail)
+	}
+}
+
+func (r *SignalRules) validate() error {
+	if r.Buy.MinMarginOfSafety <= r.Sell.MaxMarginOfSafety {
+		return fmt.Errorf("buy minMarginOfSafety must be above sell maxMarginOfSafety")
+	}
+
+	if r.Buy.MinScore < r.Sell.MinScore {
+		return fmt.Errorf("buy minScore must not be below sell minScore")
+	}
+
+	if r.Buy.MaxPriceToTwoHundredDayAverage >= r.Sell.MinPriceToTwoHundredDayAverage {
+		return fmt.Errorf("buy maxPriceToTwoHundredDayAverage must be below sell minPriceToTwoHundredDayAverage")
+	}
+
+	if r.Buy.MinBelowFiftyTwoWeekHigh < 0 || r.Buy.MinBelowFiftyTwoWeekHigh >= 1 {
+		return fmt.Errorf("buy minBelowFiftyTwoWeekHigh must be from 0 up to 1")
+	}
+
+	return nil
+}
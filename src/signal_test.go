@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignalHold(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	v, _ := NewValuation(f, DefaultDCFOptions())
+
+	// Act
+	s := NewSignal(v, ScoreValue(f, nil), f.Stock, nil)
+
+	// Assert
+	assert.Equal(t, HOLD, s.Action)
+	assert.Len(t, s.Reasons, 8)
+	assert.False(t, s.Reasons[0].Held, "margin of safety 21.95% is short of 25%")
+	assert.True(t, s.Reasons[1].Held)
+	assert.Equal(t, "0.9018", s.Reasons[2].Detail)
+}
+
+func TestSignalBuy(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	v, _ := NewValuation(f, DefaultDCFOptions())
+	rules := *DefaultRatingProfile().Signals
+	rules.Buy.MinMarginOfSafety = 0.2
+
+	// Act
+	s := NewSignal(v, ScoreValue(f, nil), f.Stock, &rules)
+
+	// Assert
+	assert.Equal(t, BUY, s.Action)
+}
+
+func TestSignalSell(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	v, _ := NewValuation(f, DefaultDCFOptions())
+	v.MarginOfSafety = -0.5
+
+	// Act
+	s := NewSignal(v, ScoreValue(f, nil), f.Stock, nil)
+
+	// Assert
+	assert.Equal(t, SELL, s.Action)
+	assert.True(t, s.Reasons[5].Held)
+}
+
+func TestSignalWithoutValuationOrPrice(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+
+	// Act
+	s := NewSignal(nil, ScoreValue(f, nil), &YahooStockInfo{}, nil)
+
+	// Assert
+	assert.Equal(t, HOLD, s.Action)
+	assert.Equal(t, "not available", s.Reasons[0].Detail)
+	assert.Equal(t, "not available", s.Reasons[3].Detail)
+	assert.Equal(t, "not available", s.Reasons[4].Detail)
+}
+
+func TestSignalNearFiftyTwoWeekHigh(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	v, _ := NewValuation(f, DefaultDCFOptions())
+	rules := *DefaultRatingProfile().Signals
+	rules.Buy.MinMarginOfSafety = 0.2
+	rules.Buy.MinBelowFiftyTwoWeekHigh = 0.25
+
+	// Act
+	s := NewSignal(v, ScoreValue(f, nil), f.Stock, &rules)
+
+	// Assert
+	assert.Equal(t, HOLD, s.Action)
+	assert.Equal(t, "price below 52 week high >= 0.25", s.Reasons[4].Rule)
+	assert.False(t, s.Reasons[4].Held, "142.99 is 21.84% below the 182.94 high")
+	assert.Equal(t, "0.2184", s.Reasons[4].Detail)
+}
+
+func TestSignalRulesValidate(t *testing.T) {
+	// Arrange
+	rules := *DefaultRatingProfile().Signals
+	rules.Sell.MaxMarginOfSafety = 0.3
+
+	// Act
+	err := rules.validate()
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,771 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Store is a SQLite database of normalised fundamentals. Every fetch of a statement or quote is kept with the
+// time it was fetched, so the fundamentals of a symbol can be read back as they were at any point in time
+type Store struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// StoredFetch is one fetch of a statement, or of a quote when Kind is "stock"
+type StoredFetch struct {
+	Provider  string
+	Symbol    string
+	Kind      string
+	Period    StatementPeriod
+	FetchedAt time.Time
+	// Values counts the line item values of a statement
+	Values int
+}
+
+// storeRow is one line item value of a stored statement
+type storeRow struct {
+	End   string
+	Item  string
+	Value int64
+}
+
+// storeMigrations upgrade the schema one version at a time, PRAGMA user_version counts those applied. Only ever
+// append to them, databases in the wild have the earlier ones applied
+var storeMigrations = []string{
+	`CREATE TABLE statements (
+		id         INTEGER PRIMARY KEY,
+		provider   TEXT NOT NULL,
+		symbol     TEXT NOT NULL,
+		kind       TEXT NOT NULL,
+		period     TEXT NOT NULL,
+		fetched_at TEXT NOT NULL
+	);
+	CREATE INDEX statements_as_of ON statements (provider, symbol, kind, period, fetched_at);
+	CREATE TABLE line_items (
+		statement_id INTEGER NOT NULL REFERENCES statements (id),
+		period_end   TEXT NOT NULL,
+		item         TEXT NOT NULL,
+		value        INTEGER NOT NULL,
+		PRIMARY KEY (statement_id, period_end, item)
+	);
+	CREATE TABLE quotes (
+		id                      INTEGER PRIMARY KEY,
+		provider                TEXT NOT NULL,
+		symbol                  TEXT NOT NULL,
+		fetched_at              TEXT NOT NULL,
+		shares_outstanding      INTEGER NOT NULL,
+		country                 TEXT NOT NULL,
+		financial_currency      TEXT NOT NULL,
+		current_price           REAL NOT NULL,
+		fifty_two_week_high     REAL NOT NULL,
+		fifty_two_week_low      REAL NOT NULL,
+		two_hundred_day_average REAL NOT NULL
+	);
+	CREATE INDEX quotes_as_of ON quotes (provider, symbol, fetched_at);`,
+}
+
+// storeTime is the layout of fetched_at, fixed width in UTC so the text sorts in time order
+const storeTime = "2006-01-02T15:04:05.000000000Z07:00"
+
+// storeIncomeItems maps a normalised line item name onto its field of a Yahoo income statement
+var storeIncomeItems = map[string]func(h *YahooIncomeStatementHistory) *YahooIncomeStatementItem{
+	"TOTALREVENUE":                 func(h *YahooIncomeStatementHistory) *YahooIncomeStatementItem { return &h.TotalRevenue },
+	"COSTOFREVENUE":                func(h *YahooIncomeStatementHistory) *YahooIncomeStatementItem { return &h.CostOfRevenue },
+	"GROSSPROFIT":                  func(h *YahooIncomeStatementHistory) *YahooIncomeStatementItem { return &h.GrossProfit },
+	"SELLINGGENERALADMINISTRATIVE": func(h *YahooIncomeStatementHistory) *YahooIncomeStatementItem { return &h.SellingGeneralAdministrative },
+	"INTERESTEXPENSE":              func(h *YahooIncomeStatementHistory) *YahooIncomeStatementItem { return &h.InterestExpense },
+	"RESEARCHDEVELOPMENT":          func(h *YahooIncomeStatementHistory) *YahooIncomeStatementItem { return &h.ResearchDevelopment },
+	"INCOMEBEFORETAX":              func(h *YahooIncomeStatementHistory) *YahooIncomeStatementItem { return &h.IncomeBeforeTax },
+	"INCOMETAXEXPENSE":             func(h *YahooIncomeStatementHistory) *YahooIncomeStatementItem { return &h.IncomeTaxExpense },
+	"NETINCOME":                    func(h *YahooIncomeStatementHistory) *YahooIncomeStatementItem { return &h.NetEarnings },
+	"DILUTEDAVERAGESHARES":         func(h *YahooIncomeStatementHistory) *YahooIncomeStatementItem { return &h.DilutedAverageShares },
+}
+
+// OpenStore opens the store at path, creating it and migrating its schema to the latest version
+func OpenStore(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	// One connection serialises the writes, SQLite locks the whole file for each anyway
+	db.SetMaxOpenConns(1)
+
+	s := &Store{db: db}
+
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("store: %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// clock returns the time fetches are stored at
+func (s *Store) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+
+	return s.now()
+}
+
+// version returns the number of migrations applied
+func (s *Store) version() (int, error) {
+	var v int
+
+	err := s.db.QueryRow("PRAGMA user_version").Scan(&v)
+
+	return v, err
+}
+
+// migrate applies the migrations the database lacks, each in its own transaction
+func (s *Store) migrate() error {
+	v, err := s.version()
+
+	if err != nil {
+		return err
+	}
+
+	if v > len(storeMigrations) {
+		return fmt.Errorf("schema version %d is newer than this build knows (%d)", v, len(storeMigrations))
+	}
+
+	for ; v < len(storeMigrations); v++ {
+		tx, err := s.db.Begin()
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(storeMigrations[v]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", v+1, err)
+		}
+
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", v+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", v+1, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d: %w", v+1, err)
+		}
+	}
+
+	return nil
+}
+
+// SaveIncomeStatement stores the annual income statements of symbol, and the quarterly ones when there are any
+func (s *Store) SaveIncomeStatement(provider, symbol string, y *YahooIncomeStatementV15) error {
+	if err := s.saveStatement(provider, symbol, "income", Annual, incomeRows(y.Root.IncomeStatementHistory)); err != nil {
+		return err
+	}
+
+	if len(y.Quarterly.IncomeStatementHistory) == 0 {
+		return nil
+	}
+
+	return s.saveStatement(provider, symbol, "income", Quarterly, incomeRows(y.Quarterly.IncomeStatementHistory))
+}
+
+// SaveBalanceSheet stores the balance sheets of symbol covering period
+func (s *Store) SaveBalanceSheet(provider, symbol string, period StatementPeriod, y *YahooBalanceSheetV1) error {
+	rows := []storeRow{}
+
+	for _, item := range y.Root {
+		rows = columnRows(rows, item.Name, item.Values)
+	}
+
+	return s.saveStatement(provider, symbol, "balance", period, rows)
+}
+
+// SaveCashFlow stores the cash flow statements of symbol covering period
+func (s *Store) SaveCashFlow(provider, symbol string, period StatementPeriod, y *YahooCashFlowV1) error {
+	rows := []storeRow{}
+
+	for _, item := range y.Root {
+		rows = columnRows(rows, item.Name, item.Values)
+	}
+
+	return s.saveStatement(provider, symbol, "cash-flow", period, rows)
+}
+
+// SaveStockInfo stores a quote of symbol. Unlike statements every quote is kept, they are the price history
+func (s *Store) SaveStockInfo(provider, symbol string, x *YahooStockInfo) error {
+	r := x.Root
+
+	_, err := s.db.Exec(
+		`INSERT INTO quotes (provider, symbol, fetched_at, shares_outstanding, country, financial_currency, current_price,
+			fifty_two_week_high, fifty_two_week_low, two_hundred_day_average) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		provider, strings.ToUpper(symbol), s.clock().UTC().Format(storeTime), r.SharesOutstanding, r.Country,
+		r.FinancialCurrency, r.CurrentPrice, r.FiftyTwoWeekHigh, r.FiftyTwoWeekLow, r.TwoHundredDayAverage,
+	)
+
+	if err != nil {
+		return fmt.Errorf("store: quote of %s: %w", symbol, err)
+	}
+
+	return nil
+}
+
+// saveStatement stores rows as a fetch of the statement now. A fetch no different to the latest one stored from
+// the same provider isn't stored again, so each stored fetch of a statement is a change to it
+func (s *Store) saveStatement(provider, symbol, kind string, period StatementPeriod, rows []storeRow) error {
+	symbol = strings.ToUpper(symbol)
+	sortRows(rows)
+
+	latest, _, err := s.statement(provider, symbol, kind, period, time.Time{})
+
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	if err == nil && reflect.DeepEqual(latest, rows) {
+		return nil
+	}
+
+	if err := s.insertStatement(provider, symbol, kind, period, rows); err != nil {
+		return fmt.Errorf("store: %s %s of %s: %w", period, kind, symbol, err)
+	}
+
+	return nil
+}
+
+func (s *Store) insertStatement(provider, symbol, kind string, period StatementPeriod, rows []storeRow) error {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		"INSERT INTO statements (provider, symbol, kind, period, fetched_at) VALUES (?, ?, ?, ?, ?)",
+		provider, symbol, kind, string(period), s.clock().UTC().Format(storeTime),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		if _, err := tx.Exec("INSERT INTO line_items (statement_id, period_end, item, value) VALUES (?, ?, ?, ?)", id, r.End, r.Item, r.Value); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// statement returns the rows of the latest fetch of the statement from provider at or before asOf, any time when
+// asOf is zero, and when it was fetched. ErrNotFound when there is none
+func (s *Store) statement(provider, symbol, kind string, period StatementPeriod, asOf time.Time) ([]storeRow, time.Time, error) {
+	var id int64
+	var fetched string
+
+	err := s.db.QueryRow(
+		`SELECT id, fetched_at FROM statements WHERE provider = ? AND symbol = ? AND kind = ? AND period = ? AND fetched_at <= ?
+			ORDER BY fetched_at DESC, id DESC LIMIT 1`,
+		provider, strings.ToUpper(symbol), kind, string(period), storeAsOf(asOf),
+	).Scan(&id, &fetched)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, time.Time{}, fmt.Errorf("store: no %s %s of %s from %s: %w", period, kind, symbol, provider, ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("store: %w", err)
+	}
+
+	rows, err := s.db.Query("SELECT period_end, item, value FROM line_items WHERE statement_id = ? ORDER BY period_end, item", id)
+
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("store: %w", err)
+	}
+
+	defer rows.Close()
+
+	x := []storeRow{}
+
+	for rows.Next() {
+		var r storeRow
+
+		if err := rows.Scan(&r.End, &r.Item, &r.Value); err != nil {
+			return nil, time.Time{}, fmt.Errorf("store: %w", err)
+		}
+
+		x = append(x, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("store: %w", err)
+	}
+
+	at, err := time.Parse(storeTime, fetched)
+
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("store: %w", err)
+	}
+
+	return x, at, nil
+}
+
+// IncomeStatement returns the income statements of symbol fetched from provider as they were at asOf, the latest
+// when asOf is zero
+func (s *Store) IncomeStatement(provider, symbol string, asOf time.Time) (*YahooIncomeStatementV15, error) {
+	annual, _, err := s.statement(provider, symbol, "income", Annual, asOf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	y := &YahooIncomeStatementV15{}
+	y.Root.IncomeStatementHistory = incomeHistory(annual)
+
+	quarterly, _, err := s.statement(provider, symbol, "income", Quarterly, asOf)
+
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	y.Quarterly.IncomeStatementHistory = incomeHistory(quarterly)
+
+	return y, nil
+}
+
+// BalanceSheet returns the balance sheets of symbol covering period fetched from provider as they were at asOf, the
+// latest when asOf is zero
+func (s *Store) BalanceSheet(provider, symbol string, period StatementPeriod, asOf time.Time) (*YahooBalanceSheetV1, error) {
+	rows, _, err := s.statement(provider, symbol, "balance", period, asOf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	y := &YahooBalanceSheetV1{}
+
+	for _, name := range rowItems(rows) {
+		y.Root = append(y.Root, YahooBalanceItem{Name: name, Values: rowValues(rows, name)})
+	}
+
+	return y, nil
+}
+
+// CashFlow returns the cash flow statements of symbol covering period fetched from provider as they were at asOf,
+// the latest when asOf is zero
+func (s *Store) CashFlow(provider, symbol string, period StatementPeriod, asOf time.Time) (*YahooCashFlowV1, error) {
+	rows, _, err := s.statement(provider, symbol, "cash-flow", period, asOf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	y := &YahooCashFlowV1{}
+
+	for _, name := range rowItems(rows) {
+		y.Root = append(y.Root, YahooCashItem{Name: name, Values: rowValues(rows, name)})
+	}
+
+	return y, nil
+}
+
+// StockInfo returns the latest quote of symbol fetched from provider at asOf, any time when asOf is zero
+func (s *Store) StockInfo(provider, symbol string, asOf time.Time) (*YahooStockInfo, error) {
+	x := &YahooStockInfo{}
+	r := &x.Root
+
+	err := s.db.QueryRow(
+		`SELECT shares_outstanding, country, financial_currency, current_price, fifty_two_week_high, fifty_two_week_low,
+			two_hundred_day_average FROM quotes WHERE provider = ? AND symbol = ? AND fetched_at <= ?
+			ORDER BY fetched_at DESC, id DESC LIMIT 1`,
+		provider, strings.ToUpper(symbol), storeAsOf(asOf),
+	).Scan(&r.SharesOutstanding, &r.Country, &r.FinancialCurrency, &r.CurrentPrice, &r.FiftyTwoWeekHigh, &r.FiftyTwoWeekLow, &r.TwoHundredDayAverage)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("store: no quote of %s from %s: %w", symbol, provider, ErrNotFound)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return x, nil
+}
+
+// Fetches returns every stored fetch of symbol, or of every symbol when symbol is empty, ordered by symbol and time
+func (s *Store) Fetches(symbol string) ([]StoredFetch, error) {
+	rows, err := s.db.Query(
+		`SELECT provider, symbol, kind, period, fetched_at, values_ FROM (
+			SELECT provider, symbol, kind, period, fetched_at, (SELECT COUNT(*) FROM line_items WHERE statement_id = id) AS values_
+				FROM statements
+			UNION ALL
+			SELECT provider, symbol, 'stock', '', fetched_at, 0 FROM quotes
+		) WHERE ? = '' OR symbol = ? ORDER BY symbol, fetched_at, kind, period`,
+		strings.ToUpper(symbol), strings.ToUpper(symbol),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	defer rows.Close()
+
+	x := []StoredFetch{}
+
+	for rows.Next() {
+		var f StoredFetch
+		var period, fetched string
+
+		if err := rows.Scan(&f.Provider, &f.Symbol, &f.Kind, &period, &fetched, &f.Values); err != nil {
+			return nil, fmt.Errorf("store: %w", err)
+		}
+
+		f.Period = StatementPeriod(period)
+
+		if f.FetchedAt, err = time.Parse(storeTime, fetched); err != nil {
+			return nil, fmt.Errorf("store: %w", err)
+		}
+
+		x = append(x, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("store: %w", err)
+	}
+
+	return x, nil
+}
+
+// PrintStoredFetches writes every stored fetch as a table
+func PrintStoredFetches(w io.Writer, x []StoredFetch) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Symbol\tFetched\tStatement\tPeriod\tValues\tProvider")
+
+	for _, f := range x {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n", f.Symbol, f.FetchedAt.Format(time.RFC3339), f.Kind, f.Period, f.Values, f.Provider)
+	}
+
+	tw.Flush()
+}
+
+// storeAsOf returns the fetched_at bound of asOf, beyond every fetch when asOf is zero
+func storeAsOf(asOf time.Time) string {
+	if asOf.IsZero() {
+		asOf = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+
+	return asOf.UTC().Format(storeTime)
+}
+
+// incomeRows flattens Yahoo income statements into one row per line item and period
+func incomeRows(history []YahooIncomeStatementHistory) []storeRow {
+	rows := []storeRow{}
+
+	for i := range history {
+		h := &history[i]
+
+		end, err := time.Parse("2006-01-02", h.EndDate.Fmt)
+
+		if err != nil {
+			continue
+		}
+
+		for name, field := range storeIncomeItems {
+			rows = append(rows, storeRow{End: end.Format("2006-01-02"), Item: name, Value: field(h).Raw})
+		}
+	}
+
+	return rows
+}
+
+// incomeHistory is the reverse of incomeRows
+func incomeHistory(rows []storeRow) []YahooIncomeStatementHistory {
+	x := []YahooIncomeStatementHistory{}
+	ends := map[string]int{}
+
+	for _, r := range rows {
+		i, ok := ends[r.End]
+
+		if !ok {
+			end, err := time.Parse("2006-01-02", r.End)
+
+			if err != nil {
+				continue
+			}
+
+			i = len(x)
+			ends[r.End] = i
+			x = append(x, YahooIncomeStatementHistory{EndDate: YahooIncomeStatementItem{Raw: end.Unix(), Fmt: r.End}})
+		}
+
+		if field, ok := storeIncomeItems[r.Item]; ok {
+			*field(&x[i]) = YahooIncomeStatementItem{Raw: r.Value, Fmt: fmt.Sprint(r.Value)}
+		}
+	}
+
+	return x
+}
+
+// columnRows appends a Yahoo line item's value per period end to rows, under its normalised name
+func columnRows(rows []storeRow, name string, values map[time.Time]int64) []storeRow {
+	for end, v := range values {
+		rows = append(rows, storeRow{End: end.UTC().Format("2006-01-02"), Item: normaliseLineItem(name), Value: v})
+	}
+
+	return rows
+}
+
+// rowItems returns the line item names of rows, in order
+func rowItems(rows []storeRow) []string {
+	seen := map[string]bool{}
+	x := []string{}
+
+	for _, r := range rows {
+		if !seen[r.Item] {
+			seen[r.Item] = true
+			x = append(x, r.Item)
+		}
+	}
+
+	sort.Strings(x)
+
+	return x
+}
+
+// rowValues returns the values of line item name by period end
+func rowValues(rows []storeRow, name string) map[time.Time]int64 {
+	x := map[time.Time]int64{}
+
+	for _, r := range rows {
+		if r.Item != name {
+			continue
+		}
+
+		if end, err := time.Parse("2006-01-02", r.End); err == nil {
+			x[end] = r.Value
+		}
+	}
+
+	return x
+}
+
+func sortRows(rows []storeRow) {
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].End != rows[j].End {
+			return rows[i].End < rows[j].End
+		}
+
+		return rows[i].Item < rows[j].Item
+	})
+}
+
+// StoringProvider passes every call through to Provider, saving each response to Store as fetched from Name
+type StoringProvider struct {
+	Provider FundamentalsProvider
+	Name     string
+	Store    *Store
+}
+
+func (p *StoringProvider) GetIncomeStatement(symbol string) (*YahooIncomeStatementV15, error) {
+	return stored(p.Provider.GetIncomeStatement, symbol, func(x *YahooIncomeStatementV15) error {
+		return p.Store.SaveIncomeStatement(p.Name, symbol, x)
+	})
+}
+
+func (p *StoringProvider) GetBalanceSheet(symbol string) (*YahooBalanceSheetV1, error) {
+	return stored(p.Provider.GetBalanceSheet, symbol, func(x *YahooBalanceSheetV1) error {
+		return p.Store.SaveBalanceSheet(p.Name, symbol, Annual, x)
+	})
+}
+
+func (p *StoringProvider) GetCashFlow(symbol string) (*YahooCashFlowV1, error) {
+	return stored(p.Provider.GetCashFlow, symbol, func(x *YahooCashFlowV1) error {
+		return p.Store.SaveCashFlow(p.Name, symbol, Annual, x)
+	})
+}
+
+func (p *StoringProvider) GetStockInfo(symbol string) (*YahooStockInfo, error) {
+	return stored(p.Provider.GetStockInfo, symbol, func(x *YahooStockInfo) error {
+		return p.Store.SaveStockInfo(p.Name, symbol, x)
+	})
+}
+
+func (p *StoringProvider) GetQuarterlyBalanceSheet(symbol string) (*YahooBalanceSheetV1, error) {
+	q, ok := p.Provider.(QuarterlyProvider)
+
+	if !ok {
+		return nil, fmt.Errorf("provider %T has no quarterly statements", p.Provider)
+	}
+
+	return stored(q.GetQuarterlyBalanceSheet, symbol, func(x *YahooBalanceSheetV1) error {
+		return p.Store.SaveBalanceSheet(p.Name, symbol, Quarterly, x)
+	})
+}
+
+func (p *StoringProvider) GetQuarterlyCashFlow(symbol string) (*YahooCashFlowV1, error) {
+	q, ok := p.Provider.(QuarterlyProvider)
+
+	if !ok {
+		return nil, fmt.Errorf("provider %T has no quarterly statements", p.Provider)
+	}
+
+	return stored(q.GetQuarterlyCashFlow, symbol, func(x *YahooCashFlowV1) error {
+		return p.Store.SaveCashFlow(p.Name, symbol, Quarterly, x)
+	})
+}
+
+// GetRawResponse passes the body of the endpoint response through, saving it to Store as fetched now. Under a
+// CachingProvider only the responses fetched over the network reach it, so a cached one isn't stored again as new
+func (p *StoringProvider) GetRawResponse(symbol, endpoint string) ([]byte, error) {
+	r, ok := p.Provider.(RawProvider)
+
+	if !ok {
+		return nil, fmt.Errorf("store: provider %T has no raw responses", p.Provider)
+	}
+
+	b, err := r.GetRawResponse(symbol, endpoint)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.saveResponse(symbol, endpoint, b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// saveResponse decodes the body of the endpoint response and saves it to Store
+func (p *StoringProvider) saveResponse(symbol, endpoint string, b []byte) error {
+	switch endpoint {
+	case "income":
+		return saveDecoded(b, endpoint, func(x *YahooIncomeStatementV15) error { return p.Store.SaveIncomeStatement(p.Name, symbol, x) })
+	case "balance":
+		return saveDecoded(b, endpoint, func(x *YahooBalanceSheetV1) error { return p.Store.SaveBalanceSheet(p.Name, symbol, Annual, x) })
+	case "cash-flow":
+		return saveDecoded(b, endpoint, func(x *YahooCashFlowV1) error { return p.Store.SaveCashFlow(p.Name, symbol, Annual, x) })
+	case "quarterly-balance":
+		return saveDecoded(b, endpoint, func(x *YahooBalanceSheetV1) error { return p.Store.SaveBalanceSheet(p.Name, symbol, Quarterly, x) })
+	case "quarterly-cash-flow":
+		return saveDecoded(b, endpoint, func(x *YahooCashFlowV1) error { return p.Store.SaveCashFlow(p.Name, symbol, Quarterly, x) })
+	case "stock":
+		return saveDecoded(b, endpoint, func(x *YahooStockInfo) error { return p.Store.SaveStockInfo(p.Name, symbol, x) })
+	}
+
+	return fmt.Errorf("store: unknown endpoint %q", endpoint)
+}
+
+// saveDecoded decodes the body of an endpoint response and saves it
+func saveDecoded[T any](b []byte, endpoint string, save func(*T) error) error {
+	x, err := decodeResponse[T](b, endpoint)
+
+	if err != nil {
+		return err
+	}
+
+	return save(x)
+}
+
+// stored fetches a response and saves it, failing when it can't be saved so no fetch is missing from the history
+func stored[T any](fetch func(string) (*T, error), symbol string, save func(*T) error) (*T, error) {
+	x, err := fetch(symbol)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := save(x); err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// StoreProvider serves the fundamentals in Store fetched from Provider as they were at AsOf, or the latest when
+// AsOf is zero
+type StoreProvider struct {
+	Store *Store
+	// Provider is the name the fundamentals were stored under, e.g. yahoo-rapidapi
+	Provider string
+	AsOf     time.Time
+}
+
+func (p *StoreProvider) GetIncomeStatement(symbol string) (*YahooIncomeStatementV15, error) {
+	return p.Store.IncomeStatement(p.Provider, symbol, p.AsOf)
+}
+
+func (p *StoreProvider) GetBalanceSheet(symbol string) (*YahooBalanceSheetV1, error) {
+	return p.Store.BalanceSheet(p.Provider, symbol, Annual, p.AsOf)
+}
+
+func (p *StoreProvider) GetCashFlow(symbol string) (*YahooCashFlowV1, error) {
+	return p.Store.CashFlow(p.Provider, symbol, Annual, p.AsOf)
+}
+
+func (p *StoreProvider) GetStockInfo(symbol string) (*YahooStockInfo, error) {
+	return p.Store.StockInfo(p.Provider, symbol, p.AsOf)
+}
+
+func (p *StoreProvider) GetQuarterlyBalanceSheet(symbol string) (*YahooBalanceSheetV1, error) {
+	return p.Store.BalanceSheet(p.Provider, symbol, Quarterly, p.AsOf)
+}
+
+func (p *StoreProvider) GetQuarterlyCashFlow(symbol string) (*YahooCashFlowV1, error) {
+	return p.Store.CashFlow(p.Provider, symbol, Quarterly, p.AsOf)
+}
+
+// Close closes the store
+func (p *StoreProvider) Close() error {
+	return p.Store.Close()
+}
+
+// ParseAsOf reads an --as-of time, either RFC 3339 or a date, which means the end of that day in UTC
+func ParseAsOf(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	d, err := time.Parse("2006-01-02", s)
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("as of %q: expected a date such as 2023-01-31 or an RFC 3339 time", s)
+	}
+
+	return d.Add(24*time.Hour - time.Nanosecond), nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestStore(t *testing.T) *Store {
+	s, err := OpenStore(filepath.Join(t.TempDir(), "finance.db"))
+
+	assert.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+func TestStoreProviderReadsBack(t *testing.T) {
+	// Arrange
+	s := openTestStore(t)
+	fetched, err := LoadFundamentalsFor(&StoringProvider{Provider: &YahooMockClient{}, Name: "mock", Store: s}, "AAPL", TTM)
+	assert.NoError(t, err)
+
+	// Act
+	stored, err := LoadFundamentalsFor(&StoreProvider{Store: s, Provider: "mock"}, "aapl", TTM)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", stored.Currency())
+	assert.Equal(t, fetched.Income.Ends(), stored.Income.Ends())
+	assert.Equal(t, fetched.Balance.Ends(), stored.Balance.Ends())
+	assert.Equal(t, ScoreValue(fetched, nil).Total, ScoreValue(stored, nil).Total)
+}
+
+func TestStoreAsOf(t *testing.T) {
+	// Arrange
+	s := openTestStore(t)
+	first := time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 9, 25, 0, 0, 0, 0, time.UTC)
+	balance := func(v int64) *YahooBalanceSheetV1 {
+		return &YahooBalanceSheetV1{Root: []YahooBalanceItem{{Name: "Total Assets", Values: map[time.Time]int64{end: v}}}}
+	}
+
+	s.now = func() time.Time { return first }
+	assert.NoError(t, s.SaveBalanceSheet("mock", "ACME", Annual, balance(100)))
+	s.now = func() time.Time { return first.Add(24 * time.Hour) }
+	assert.NoError(t, s.SaveBalanceSheet("mock", "ACME", Annual, balance(120)))
+
+	// Act
+	then, thenErr := s.BalanceSheet("mock", "ACME", Annual, first.Add(time.Hour))
+	latest, latestErr := s.BalanceSheet("mock", "ACME", Annual, time.Time{})
+	_, beforeErr := s.BalanceSheet("mock", "ACME", Annual, first.Add(-time.Hour))
+
+	// Assert
+	assert.NoError(t, thenErr)
+	assert.NoError(t, latestErr)
+	assert.Equal(t, int64(100), then.Root[0].Values[end])
+	assert.Equal(t, int64(120), latest.Root[0].Values[end])
+	assert.Equal(t, "TOTALASSETS", latest.Root[0].Name)
+	assert.ErrorIs(t, beforeErr, ErrNotFound)
+}
+
+func TestStoreSkipsUnchangedStatements(t *testing.T) {
+	// Arrange
+	s := openTestStore(t)
+	p := &StoringProvider{Provider: &YahooMockClient{}, Name: "mock", Store: s}
+	LoadFundamentals(p, "AAPL")
+
+	// Act
+	_, err := LoadFundamentals(p, "AAPL")
+	x, _ := s.Fetches("AAPL")
+
+	// Assert
+	assert.NoError(t, err)
+
+	kinds := map[string]int{}
+	for _, f := range x {
+		kinds[f.Kind]++
+	}
+
+	assert.Equal(t, map[string]int{"income": 2, "balance": 1, "cash-flow": 1, "stock": 2}, kinds)
+}
+
+func TestStoreKeepsProvidersApart(t *testing.T) {
+	// Arrange
+	s := openTestStore(t)
+	end := time.Date(2021, 9, 25, 0, 0, 0, 0, time.UTC)
+	balance := func(v int64) *YahooBalanceSheetV1 {
+		return &YahooBalanceSheetV1{Root: []YahooBalanceItem{{Name: "Total Assets", Values: map[time.Time]int64{end: v}}}}
+	}
+
+	assert.NoError(t, s.SaveBalanceSheet("yahoo-rapidapi", "ACME", Annual, balance(100)))
+	assert.NoError(t, s.SaveBalanceSheet("edgar", "ACME", Annual, balance(100)))
+	assert.NoError(t, s.SaveBalanceSheet("csv", "ACME", Annual, balance(90)))
+	assert.NoError(t, s.SaveStockInfo("csv", "ACME", &YahooStockInfo{}))
+
+	// Act
+	yahoo, yahooErr := s.BalanceSheet("yahoo-rapidapi", "ACME", Annual, time.Time{})
+	_, quoteErr := s.StockInfo("yahoo-rapidapi", "ACME", time.Time{})
+	x, _ := s.Fetches("ACME")
+
+	// Assert
+	assert.NoError(t, yahooErr)
+	assert.Equal(t, int64(100), yahoo.Root[0].Values[end])
+	assert.ErrorIs(t, quoteErr, ErrNotFound)
+	assert.Len(t, x, 4, "the same statement fetched from another provider is stored again")
+}
+
+func TestStoreUnderCacheSavesOnlyFetches(t *testing.T) {
+	// Arrange
+	s := openTestStore(t)
+	y, hits := yahooTestServer(t)
+	p := storingProvider(&CachingProvider{Provider: y, Name: "yahoo-rapidapi", Cache: NewCache(t.TempDir())}, "yahoo-rapidapi", s)
+	first := time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return first }
+	LoadFundamentals(p, "AAPL")
+	s.now = func() time.Time { return first.Add(time.Minute) }
+
+	// Act
+	_, err := LoadFundamentals(p, "AAPL")
+	x, _ := s.Fetches("AAPL")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hits["/stock-info"])
+	assert.Len(t, x, 5, "the cached responses of the second run aren't stored again")
+
+	for _, f := range x {
+		assert.Equal(t, first, f.FetchedAt)
+	}
+}
+
+func TestStoreMigratesOnce(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "finance.db")
+	s, _ := OpenStore(path)
+	s.Close()
+
+	// Act
+	s, err := OpenStore(path)
+	v, _ := s.version()
+	s.Close()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, len(storeMigrations), v)
+}
+
+func TestParseAsOf(t *testing.T) {
+	// Act
+	day, dayErr := ParseAsOf("2023-01-31")
+	exact, exactErr := ParseAsOf("2023-01-31T09:30:00Z")
+	_, badErr := ParseAsOf("31/01/2023")
+
+	// Assert
+	assert.NoError(t, dayErr)
+	assert.NoError(t, exactErr)
+	assert.Equal(t, time.Date(2023, 1, 31, 23, 59, 59, 999999999, time.UTC), day)
+	assert.Equal(t, time.Date(2023, 1, 31, 9, 30, 0, 0, time.UTC), exact)
+	assert.Error(t, badErr)
+}
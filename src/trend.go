@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Trend describes how consistently a metric moved over the loaded years
+type Trend struct {
+	Rating Rating
+	Values []float64
+	// Monotonic is true when the metric never fell on the year before
+	Monotonic bool
+	DownYears int
+	// CoefficientOfVariation is the standard deviation / mean, lower is steadier
+	CoefficientOfVariation float64
+	// CAGR is the compound annual growth rate from the first to the last period,
+	// NaN when either end is zero or negative
+	CAGR float64
+}
+
+// TrendRating rates the multi-year history of a business, rather than single year snapshots
+type TrendRating struct {
+	income   *IncomeStatement
+	balance  *BalanceSheet
+	cashFlow *CashFlowStatement
+	profile  *RatingProfile
+}
+
+// trendMetric is a metric whose history should rise steadily
+type trendMetric struct {
+	name   string
+	values func(t *TrendRating) ([]float64, []time.Time)
+}
+
+var trendMetrics = []trendMetric{
+	{name: "totalRevenue", values: func(t *TrendRating) ([]float64, []time.Time) {
+		return incomeValues(t.income, func(y *YearIncomeStatement) float64 { return y.TotalRevenue().Float64() })
+	}},
+	{name: "grossProfitMargin", values: func(t *TrendRating) ([]float64, []time.Time) {
+		return incomeRatios(t.income, (*YearIncomeStatement).GrossProfitMargin)
+	}},
+	{name: "netEarnings", values: func(t *TrendRating) ([]float64, []time.Time) {
+		return incomeValues(t.income, func(y *YearIncomeStatement) float64 { return y.NetEarnings().Float64() })
+	}},
+	{name: "perShareEarnings", values: func(t *TrendRating) ([]float64, []time.Time) {
+		return incomeRatios(t.income, (*YearIncomeStatement).perShareEarnings)
+	}},
+	{name: "freeCashFlow", values: func(t *TrendRating) ([]float64, []time.Time) {
+		x := []float64{}
+		for _, y := range t.cashFlow.Values() {
+			x = append(x, y.FreeCashFlow().Float64())
+		}
+		return x, t.cashFlow.Ends()
+	}},
+	{name: "shareholdersEquity", values: func(t *TrendRating) ([]float64, []time.Time) {
+		x := []float64{}
+		for _, y := range t.balance.Values() {
+			x = append(x, y.TotalShareholdersEquity().Float64())
+		}
+		return x, t.balance.Ends()
+	}},
+}
+
+// NewTrend measures the history in values (oldest first, a year apart) and rates it against rules, nil uses
+// the default profile
+func NewTrend(values []float64, rules *TrendProfile) Trend {
+	return newTrend(values, nil, rules)
+}
+
+// newTrend is NewTrend for values of the periods ending at ends, so CAGR is a yearly rate over the time between
+// the first and last period, e.g. of quarters or of a trailing twelve months after the last fiscal year.
+// nil ends are a year apart
+func newTrend(values []float64, ends []time.Time, rules *TrendProfile) Trend {
+	if rules == nil {
+		rules = DefaultRatingProfile().Trend
+	}
+
+	t := Trend{Values: values, CAGR: math.NaN()}
+
+	for i := 1; i < len(values); i++ {
+		if values[i] < values[i-1] {
+			t.DownYears++
+		}
+	}
+
+	t.Monotonic = t.DownYears == 0
+
+	m := mean(values)
+	t.CoefficientOfVariation = math.Sqrt(sampleVariance(values)) / math.Abs(m)
+
+	years := float64(len(values) - 1)
+	if len(ends) == len(values) && len(ends) > 1 {
+		years = yearsBetween(ends[0], ends[len(ends)-1])
+	}
+
+	if years > 0 && values[0] > 0 && values[len(values)-1] > 0 {
+		t.CAGR = math.Pow(values[len(values)-1]/values[0], 1/years) - 1
+	}
+
+	switch {
+	case len(values) < 2:
+		t.Rating = BAD
+	case t.meets(rules.Good):
+		t.Rating = GOOD
+	case t.meets(rules.OK):
+		t.Rating = OK
+	default:
+		t.Rating = BAD
+	}
+
+	return t
+}
+
+// yearsBetween is the time from one period end to another in years, to the nearest month so 52/53 week fiscal
+// years and quarters count as whole ones
+func yearsBetween(from, to time.Time) float64 {
+	months := to.Sub(from).Hours() / 24 / 365.25 * 12
+
+	return math.Round(months) / 12
+}
+
+func (t Trend) meets(r TrendRules) bool {
+	// NaN fails every comparison, so unmeasurable growth or variation never passes
+	return t.DownYears <= r.MaxDownYears && t.CAGR >= r.MinCAGR && t.CoefficientOfVariation <= r.MaxVariation
+}
+
+// NewTrendRating rates the history of f against profile, nil uses the default profile
+func NewTrendRating(f *Fundamentals, profile *RatingProfile) *TrendRating {
+	if profile == nil {
+		profile = DefaultRatingProfile()
+	}
+
+	return &TrendRating{income: f.Income, balance: f.Balance, cashFlow: f.CashFlow, profile: profile}
+}
+
+// Trends returns the Trend of every metric, by metric name
+func (I *TrendRating) Trends() map[string]Trend {
+	x := map[string]Trend{}
+
+	for _, m := range trendMetrics {
+		values, ends := m.values(I)
+		x[m.name] = newTrend(values, ends, I.profile.Trend)
+	}
+
+	return x
+}
+
+// Print writes the trend of every metric as a table
+func (I *TrendRating) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Trend\tYears\tDown Years\tCAGR\tVariation\tRating")
+
+	trends := I.Trends()
+
+	for _, m := range trendMetrics {
+		t := trends[m.name]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.4f\t%.4f\t%s\n", m.name, len(t.Values), t.DownYears, t.CAGR, t.CoefficientOfVariation, t.Rating)
+	}
+
+	tw.Flush()
+}
+
+// incomeValues returns f of every period, and the period ends
+func incomeValues(I *IncomeStatement, f func(y *YearIncomeStatement) float64) ([]float64, []time.Time) {
+	x := []float64{}
+
+	for _, y := range I.Values() {
+		x = append(x, f(y))
+	}
+
+	return x, I.Ends()
+}
+
+// incomeRatios is incomeValues for a ratio, skipping the periods where it isn't meaningful
+func incomeRatios(I *IncomeStatement, f func(y *YearIncomeStatement) (decimal.Decimal, error)) ([]float64, []time.Time) {
+	x := []float64{}
+	ends := []time.Time{}
+
+	for _, p := range I.Periods() {
+		if v, err := ratioFloat(f(p.Value)); err == nil {
+			x = append(x, v)
+			ends = append(ends, p.End)
+		}
+	}
+
+	return x, ends
+}
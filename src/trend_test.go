@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrendSteadyGrowth(t *testing.T) {
+	// Act
+	tr := NewTrend([]float64{100, 110, 121, 133.1}, nil)
+
+	// Assert
+	assert.True(t, tr.Monotonic)
+	assert.Zero(t, tr.DownYears)
+	assert.InDelta(t, 0.1, tr.CAGR, 1e-9)
+	assert.Equal(t, GOOD, tr.Rating)
+}
+
+func TestTrendDownYears(t *testing.T) {
+	// Act
+	tr := NewTrend([]float64{100, 90, 120, 80, 130}, nil)
+
+	// Assert
+	assert.False(t, tr.Monotonic)
+	assert.Equal(t, 2, tr.DownYears)
+	assert.Equal(t, BAD, tr.Rating)
+}
+
+func TestTrendLossesHaveNoCAGR(t *testing.T) {
+	// Act
+	tr := NewTrend([]float64{-10, 5, 20}, nil)
+
+	// Assert
+	assert.True(t, math.IsNaN(tr.CAGR))
+	assert.Equal(t, BAD, tr.Rating)
+}
+
+func TestTrendRating(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+
+	// Act
+	trends := NewTrendRating(f, nil).Trends()
+
+	// Assert
+	assert.Len(t, trends, len(trendMetrics))
+	assert.Equal(t, OK, trends["totalRevenue"].Rating)
+	assert.Equal(t, BAD, trends["shareholdersEquity"].Rating)
+}
+
+func TestTrendQuarterlyCAGRIsYearly(t *testing.T) {
+	// Arrange
+	values := []float64{100, 110, 121, 133.1, 146.41}
+	ends := []time.Time{}
+	for _, e := range []string{"2020-12-26", "2021-03-27", "2021-06-26", "2021-09-25", "2021-12-25"} {
+		end, _ := time.Parse("2006-01-02", e)
+		ends = append(ends, end)
+	}
+
+	// Act
+	tr := newTrend(values, ends, nil)
+
+	// Assert
+	assert.InDelta(t, 1.1*1.1*1.1*1.1-1, tr.CAGR, 1e-9)
+}
+
+func TestTrendTrailingTwelveMonthsCAGR(t *testing.T) {
+	// Arrange
+	values := []float64{100, 110, 121, 124}
+	ends := []time.Time{}
+	for _, e := range []string{"2019-09-28", "2020-09-26", "2021-09-25", "2021-12-25"} {
+		end, _ := time.Parse("2006-01-02", e)
+		ends = append(ends, end)
+	}
+
+	// Act
+	tr := newTrend(values, ends, nil)
+
+	// Assert
+	assert.InDelta(t, math.Pow(1.24, 1/2.25)-1, tr.CAGR, 1e-9, "the TTM is a quarter after the last fiscal year, not a year")
+}
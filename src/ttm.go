@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// quartersInYear is how far apart the first and last of four consecutive quarter ends can be
+const quartersInYear = 300 * 24 * time.Hour
+
+// TrailingTwelveMonths adds the trailing twelve months to the latest quarter as the newest period of annual.
+// Flow items (income and cash flow) are summed over the last four quarters, the balance sheet is the
+// latest quarter's. Trends grow to it over the months since the last fiscal year end. annual is returned as is
+// when the latest quarter ends the latest fiscal year
+func TrailingTwelveMonths(annual *Fundamentals, quarterly *Fundamentals) (*Fundamentals, error) {
+	ends := quarterly.Income.Ends()
+
+	if len(ends) < 4 {
+		return nil, fmt.Errorf("ttm: %d quarterly income statements, need 4: %w", len(ends), ErrNotEnoughData)
+	}
+
+	ends = ends[len(ends)-4:]
+	end := ends[3]
+
+	if end.Sub(ends[0]) > quartersInYear {
+		return nil, fmt.Errorf("ttm: the last 4 quarters from %s to %s are not consecutive: %w",
+			ends[0].Format("2006-01-02"), end.Format("2006-01-02"), ErrNotEnoughData)
+	}
+
+	incomes := []*YearIncomeStatement{}
+	cashFlows := []*YearCashFlowStatement{}
+
+	for _, e := range ends {
+		c := quarterly.CashFlow.At(e)
+
+		if c == nil {
+			return nil, fmt.Errorf("ttm: no %s quarterly cash flow statement: %w", e.Format("2006-01-02"), ErrNotEnoughData)
+		}
+
+		incomes = append(incomes, quarterly.Income.At(e))
+		cashFlows = append(cashFlows, c)
+	}
+
+	balance := quarterly.Balance.At(end)
+
+	if balance == nil {
+		return nil, fmt.Errorf("ttm: no %s quarterly balance sheet: %w", end.Format("2006-01-02"), ErrNotEnoughData)
+	}
+
+	f := &Fundamentals{
+		Symbol:   annual.Symbol,
+		Period:   TTM,
+		Income:   &IncomeStatement{},
+		Balance:  &BalanceSheet{},
+		CashFlow: &CashFlowStatement{},
+		Stock:    annual.Stock,
+	}
+
+	for _, p := range annual.Income.Periods() {
+		f.Income.Set(p.End, p.Value)
+	}
+
+	for _, p := range annual.Balance.Periods() {
+		f.Balance.Set(p.End, p.Value)
+	}
+
+	for _, p := range annual.CashFlow.Periods() {
+		f.CashFlow.Set(p.End, p.Value)
+	}
+
+	// The latest fiscal year already covers these four quarters
+	if latest, ok := annual.Income.Latest(); ok && !latest.End.Before(end) {
+		return f, nil
+	}
+
+	f.Income.Set(end, sumIncome(incomes))
+	f.Balance.Set(end, balance)
+	f.CashFlow.Set(end, sumCashFlow(cashFlows))
+
+	return f, nil
+}
+
+// sumIncome adds up quarterly income statements, shares are the average of the quarters
+func sumIncome(x []*YearIncomeStatement) *YearIncomeStatement {
+	last := x[len(x)-1]
+	s := &YearIncomeStatement{Year: last.Year, End: last.End, Currency: last.Currency}
+
+	for _, q := range x {
+		s.totalRevenue = s.totalRevenue.Add(q.totalRevenue)
+		s.costOfRevenue = s.costOfRevenue.Add(q.costOfRevenue)
+		s.sellingGeneralAdministrative = s.sellingGeneralAdministrative.Add(q.sellingGeneralAdministrative)
+		s.interestExpense = s.interestExpense.Add(q.interestExpense)
+		s.researchDevelopment = s.researchDevelopment.Add(q.researchDevelopment)
+		s.incomeBeforeTax = s.incomeBeforeTax.Add(q.incomeBeforeTax)
+		s.incomeTaxExpense = s.incomeTaxExpense.Add(q.incomeTaxExpense)
+		s.netEarnings = s.netEarnings.Add(q.netEarnings)
+		s.sharesOutstanding += q.sharesOutstanding
+		s.latestShares = s.latestShares || q.latestShares
+	}
+
+	s.sharesOutstanding /= int64(len(x))
+
+	return s
+}
+
+// sumCashFlow adds up quarterly cash flow statements
+func sumCashFlow(x []*YearCashFlowStatement) *YearCashFlowStatement {
+	last := x[len(x)-1]
+	s := &YearCashFlowStatement{Year: last.Year, End: last.End, Currency: last.Currency}
+
+	for _, q := range x {
+		s.netEarnings = s.netEarnings.Add(q.netEarnings)
+		s.depreciation = s.depreciation.Add(q.depreciation)
+		s.changeToNetEarnings = s.changeToNetEarnings.Add(q.changeToNetEarnings)
+		s.changeToAccountReceivables = s.changeToAccountReceivables.Add(q.changeToAccountReceivables)
+		s.changeToLiabilities = s.changeToLiabilities.Add(q.changeToLiabilities)
+		s.changeToInventory = s.changeToInventory.Add(q.changeToInventory)
+		s.changeToOperatingActivities = s.changeToOperatingActivities.Add(q.changeToOperatingActivities)
+		s.totalCashFromOperatingActivities = s.totalCashFromOperatingActivities.Add(q.totalCashFromOperatingActivities)
+		s.capitalExpenditures = s.capitalExpenditures.Add(q.capitalExpenditures)
+		s.investments = s.investments.Add(q.investments)
+		s.otherCashflowsFromInvestingActivities = s.otherCashflowsFromInvestingActivities.Add(q.otherCashflowsFromInvestingActivities)
+		s.totalCashflowsFromInvestingActivities = s.totalCashflowsFromInvestingActivities.Add(q.totalCashflowsFromInvestingActivities)
+		s.dividendsPaid = s.dividendsPaid.Add(q.dividendsPaid)
+		s.netBorrowings = s.netBorrowings.Add(q.netBorrowings)
+		s.issuanceOfStock = s.issuanceOfStock.Add(q.issuanceOfStock)
+		s.repurchaseOfStock = s.repurchaseOfStock.Add(q.repurchaseOfStock)
+		s.otherCashflowsFromFinancingActivities = s.otherCashflowsFromFinancingActivities.Add(q.otherCashflowsFromFinancingActivities)
+		s.totalCashFromFinancingActivities = s.totalCashFromFinancingActivities.Add(q.totalCashFromFinancingActivities)
+		s.changeInCash = s.changeInCash.Add(q.changeInCash)
+	}
+
+	return s
+}
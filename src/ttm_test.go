@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadQuarterlyFundamentals(t *testing.T) {
+	// Arrange
+	p := &YahooMockClient{}
+
+	// Act
+	f, err := LoadQuarterlyFundamentals(p, "AAPL")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, Quarterly, f.Period)
+	assert.Equal(t, 4, f.Income.Len())
+	assert.Equal(t, 4, f.Balance.Len())
+	assert.Equal(t, 4, f.CashFlow.Len())
+	assert.Equal(t, int64(123945000000), f.Income.At(time.Date(2021, 12, 25, 0, 0, 0, 0, time.UTC)).TotalRevenue().Units())
+}
+
+func TestLoadQuarterlyFundamentalsUnsupported(t *testing.T) {
+	// Arrange
+	p := &EdgarFileClient{Path: "./json/edgar"}
+
+	// Act
+	_, err := LoadQuarterlyFundamentals(p, "AAPL")
+
+	// Assert
+	assert.ErrorContains(t, err, "no quarterly statements")
+}
+
+func TestTrailingTwelveMonths(t *testing.T) {
+	// Arrange
+	p := &YahooMockClient{}
+
+	// Act
+	f, err := LoadFundamentalsFor(p, "AAPL", TTM)
+
+	// Assert
+	end := time.Date(2022, 6, 25, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, TTM, f.Period)
+	assert.Equal(t, 5, f.Income.Len(), "four fiscal years and the trailing twelve months")
+
+	i := f.Income.At(end)
+	assert.Equal(t, int64(82959000000+97278000000+123945000000+83360000000), i.TotalRevenue().Units())
+	assert.Equal(t, int64(19442000000+25010000000+34630000000+20551000000), i.NetEarnings().Units())
+
+	c := f.CashFlow.At(end)
+	assert.Equal(t, int64(20200000000+46966000000+28166000000+22892000000), c.TotalCashFromOperatingActivities().Units())
+	assert.Equal(t, int64(-(3223000000 + 2803000000 + 2514000000 + 2102000000)), c.CapitalExpenditures().Units())
+
+	// Latest balance sheet, not a sum
+	assert.Equal(t, int64(336309000000), f.Balance.At(end).TotalAssets().Units())
+}
+
+func TestTrailingTwelveMonthsAtFiscalYearEnd(t *testing.T) {
+	// Arrange
+	annual, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	quarterly, _ := LoadQuarterlyFundamentals(&YahooMockClient{}, "AAPL")
+	end := time.Date(2022, 6, 25, 0, 0, 0, 0, time.UTC)
+	annual.Income.Set(end, &YearIncomeStatement{Year: 2022, End: end})
+
+	// Act
+	f, err := TrailingTwelveMonths(annual, quarterly)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 5, f.Income.Len())
+	assert.Zero(t, f.Income.At(end).TotalRevenue(), "the fiscal year is kept rather than summing quarters")
+	assert.Nil(t, f.Balance.At(end))
+}
+
+func TestTrailingTwelveMonthsNotEnoughQuarters(t *testing.T) {
+	// Arrange
+	annual, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	quarterly := &Fundamentals{Income: &IncomeStatement{}, Balance: &BalanceSheet{}, CashFlow: &CashFlowStatement{}}
+
+	// Act
+	_, err := TrailingTwelveMonths(annual, quarterly)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotEnoughData)
+}
+
+func TestQuarterlyValuationNeedsYearlyFigures(t *testing.T) {
+	// Arrange
+	f, _ := LoadQuarterlyFundamentals(&YahooMockClient{}, "AAPL")
+
+	// Act
+	_, err := NewValuation(f, DefaultDCFOptions())
+	_, err2 := NewEquityBond(f, DefaultEquityBondOptions())
+
+	// Assert
+	assert.ErrorIs(t, err, ErrNotYearly)
+	assert.ErrorIs(t, err2, ErrNotYearly)
+}
+
+func TestParseStatementPeriod(t *testing.T) {
+	// Act
+	p, err := ParseStatementPeriod("ttm")
+	_, bad := ParseStatementPeriod("monthly")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, TTM, p)
+	assert.Error(t, bad)
+}
@@ -1,5 +1,12 @@
 package main
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
 // Rating rates an IncomeStatement attribute from GOOD, OK to BAD
 type Rating int
 
@@ -9,99 +16,169 @@ const (
 	BAD
 )
 
+var ratingNames = []string{"GOOD", "OK", "BAD"}
+
+// String returns GOOD, OK or BAD
+func (r Rating) String() string {
+	if r < GOOD || r > BAD {
+		return fmt.Sprintf("Rating(%d)", int(r))
+	}
+
+	return ratingNames[r]
+}
+
+// MarshalText lets ratings be written by name
+func (r Rating) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText reads a rating by name, e.g. in a rating profile
+func (r *Rating) UnmarshalText(b []byte) error {
+	for i, name := range ratingNames {
+		if strings.EqualFold(string(b), name) {
+			*r = Rating(i)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown rating %q, expected GOOD, OK or BAD", string(b))
+}
+
 // ValueRating rates the Finances of a business in terms of Value Investing
 type ValueRating struct {
 	income  *YearIncomeStatement
 	balance *YearBalanceSheet
+	// opening is the year before's balance sheet, for average balances
+	opening *YearBalanceSheet
+	// periodsPerYear annualises flow items, 4 when income is a quarter's
+	periodsPerYear int
+	profile        *RatingProfile
 }
 
-type LegitimacyRating struct {
-	income *YearIncomeStatement
+// NewValueRating rates one fiscal year against profile, nil uses the default profile
+func NewValueRating(income *YearIncomeStatement, balance *YearBalanceSheet, profile *RatingProfile) *ValueRating {
+	if profile == nil {
+		profile = DefaultRatingProfile()
+	}
+
+	return &ValueRating{income: income, balance: balance, periodsPerYear: 1, profile: profile}
 }
 
-func (I *ValueRating) GrossProfit() Rating {
-	gpm := I.income.GrossProfitMargin()
+// WithOpeningBalance averages opening and closing balances for the returns and leverage,
+// without it they use the closing balance alone
+func (I *ValueRating) WithOpeningBalance(opening *YearBalanceSheet) *ValueRating {
+	I.opening = opening
 
-	if gpm < 0.4 {
-		return GOOD
-	} else if gpm < 0.375 {
-		return OK
-	} else {
-		return BAD
-	}
+	return I
 }
 
-func (I *ValueRating) SellingGeneralAdministrativeMargin() Rating {
-	m := I.income.SellingGeneralAdministrativeMargin()
+// WithPeriodsPerYear annualises earnings and revenue of a period this many make up a year, e.g. 4 for quarters,
+// so the debt years to repay and returns rate against the yearly bands
+func (I *ValueRating) WithPeriodsPerYear(n int) *ValueRating {
+	I.periodsPerYear = n
 
-	if m < 0.3 {
-		return GOOD
-	} else if m > 0.3 && m < 0.79 {
-		return OK
-	} else if m > 0.8 {
-		return BAD
-	}
+	return I
+}
 
-	return BAD
+func (I *ValueRating) GrossProfit() Rating {
+	return I.rate("grossProfitMargin", I.income.GrossProfitMargin)
+}
+
+func (I *ValueRating) SellingGeneralAdministrativeMargin() Rating {
+	return I.rate("sellingGeneralAdministrativeMargin", I.income.SellingGeneralAdministrativeMargin)
 }
 
 func (I *ValueRating) InterestExpenseMargin() Rating {
-	m := I.income.InterestExpenseMargin()
+	return I.rate("interestExpenseMargin", I.income.InterestExpenseMargin)
+}
 
-	if m < 0.15 {
-		return GOOD
-	} else if m > 0.15 && m < 0.35 {
-		return OK
-	}
+func (I *ValueRating) ResearchDevelopmentMargin() Rating {
+	return I.rate("researchDevelopmentMargin", I.income.ResearchDevelopmentMargin)
+}
 
-	return BAD
+// CurrentRatio
+func (I *ValueRating) CurrentRatio() Rating {
+	return I.rate("currentRatio", I.balance.CurrentRatio)
 }
 
-func (I *ValueRating) ResearchDevelopmentMargin() Rating {
-	r := I.income.ResearchDevelopmentMargin()
+// DebtToShareholderEquityRatio
+func (I *ValueRating) DebtToShareholderEquityRatio() Rating {
+	return I.rate("debtToShareholderEquityRatio", I.balance.DebtToShareholderEquityRatio)
+}
+
+// LongTermDebtYearsToRepay (LongTermDebt / yearly NetEarnings), needs both the income statement and balance sheet
+func (I *ValueRating) LongTermDebtYearsToRepay() Rating {
+	return I.rate("longTermDebtYearsToRepay", I.longTermDebtYearsToRepay)
+}
 
-	if r < 0.1 {
+// ShortVsLongTermDebt
+func (I *ValueRating) ShortVsLongTermDebt() Rating {
+	if I.balance.ShortTermDebt().Amount.LessThan(I.balance.LongTermDebt().Amount) {
 		return GOOD
-	} else if r > 0.1 && r < 0.25 {
-		return OK
 	}
 
 	return BAD
 }
 
-// IncomeTaxExpense
-func (I *LegitimacyRating) IncomeTaxExpense() Rating {
-	// t := I.statement.IncomeTaxExpense()
+// DuPont decomposes return on shareholders equity, needs both the income statement and balance sheet
+func (I *ValueRating) DuPont() (DuPont, error) {
+	d, err := NewDuPont(I.income, I.balance, I.opening)
 
-	// Compare against income taxes paid
-	// Review how much tax they should have to pay in UK/Country??? 19% in the UK???
+	return d.Annualised(I.periodsPerYear), err
+}
 
-	return BAD
+// ReturnOnShareholdersEquity (NetEarnings / Average Shareholders Equity)
+func (I *ValueRating) ReturnOnShareholdersEquity() Rating {
+	return I.rate("returnOnShareholdersEquity", I.returnOnShareholdersEquity)
 }
 
-// CurrentRatio
-func (I *ValueRating) CurrentRatio() Rating {
-	if I.balance.CurrentRatio() > 1 {
-		return GOOD
-	}
+// ReturnOnTotalAssets (NetEarnings / Average Total Assets)
+func (I *ValueRating) ReturnOnTotalAssets() Rating {
+	return I.rate("returnOnTotalAssets", I.returnOnTotalAssets)
+}
 
-	return BAD
+// Leverage (Average Total Assets / Average Shareholders Equity)
+func (I *ValueRating) Leverage() Rating {
+	return I.rate("leverage", I.leverage)
 }
 
-// DebtToShareholderEquityRatio
-func (I *ValueRating) DebtToShareholderEquityRatio() Rating {
-	if I.balance.DebtToShareholderEquityRatio() <= 0.8 {
-		return GOOD
+func (I *ValueRating) longTermDebtYearsToRepay() (decimal.Decimal, error) {
+	years, err := I.balance.LongTermDebtYearsToRepay(I.income.NetEarnings())
+
+	if err != nil {
+		return years, err
 	}
 
-	return BAD
+	return years.Div(decimal.New(int64(I.periodsPerYear), 0)), nil
 }
 
-// ShortVsLongTermDebt
-func (I *ValueRating) ShortVsLongTermDebt() Rating {
-	if I.balance.ShortTermDebt() < I.balance.LongTermDebt() {
-		return GOOD
+func (I *ValueRating) returnOnShareholdersEquity() (decimal.Decimal, error) {
+	d, err := I.DuPont()
+
+	return d.ReturnOnShareholdersEquity, err
+}
+
+func (I *ValueRating) returnOnTotalAssets() (decimal.Decimal, error) {
+	d, err := I.DuPont()
+
+	return d.ReturnOnTotalAssets, err
+}
+
+func (I *ValueRating) leverage() (decimal.Decimal, error) {
+	d, err := I.DuPont()
+
+	return d.Leverage, err
+}
+
+// rate rates a ratio against the profile, BAD when it isn't meaningful
+func (I *ValueRating) rate(metric string, ratio func() (decimal.Decimal, error)) Rating {
+	v, err := ratioFloat(ratio())
+
+	if err != nil {
+		return BAD
 	}
 
-	return BAD
+	r, _ := I.profile.Rate(metric, v)
+
+	return r
 }
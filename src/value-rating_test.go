@@ -2,20 +2,88 @@ package main
 
 import (
 	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestValueRatingDebtToShareholderEquity(t *testing.T) {
 	// Arrange
+	m := YahooMockClient{}
+	ybs, _ := m.GetBalanceSheet("AAPL")
+	bs := NewBalanceSheet(ybs)
+	v := NewValueRating(nil, bs.Year(2021), nil)
 
 	// Act
+	r := v.DebtToShareholderEquityRatio()
 
 	// Assert
+	assert.Equal(t, BAD, r)
 }
 
 func TestValueRatingShortVsLongTermDebt(t *testing.T) {
 	// Arrange
+	m := YahooMockClient{}
+	ybs, _ := m.GetBalanceSheet("AAPL")
+	bs := NewBalanceSheet(ybs)
+	v := NewValueRating(nil, bs.Year(2021), nil)
+
+	// Act
+	r := v.ShortVsLongTermDebt()
+
+	// Assert
+	assert.Equal(t, GOOD, r)
+}
+
+func TestValueRatingGrossProfit(t *testing.T) {
+	// Arrange
+	m := YahooMockClient{}
+	y, _ := m.GetIncomeStatement("AAPL")
+	s, _ := m.GetStockInfo("AAPL")
+	x := NewIncomeStatement(y, s)
+
+	// Act
+	r2018 := NewValueRating(x.Year(2018), nil, nil).GrossProfit()
+	r2021 := NewValueRating(x.Year(2021), nil, nil).GrossProfit()
+
+	// Assert
+	assert.Equal(t, GOOD, r2018)
+	assert.Equal(t, BAD, r2021)
+}
+
+func TestValueRatingReturnsAndLeverage(t *testing.T) {
+	// Arrange
+	f, _ := LoadFundamentals(&YahooMockClient{}, "AAPL")
+	v := NewValueRating(f.Income.Year(2019), f.Balance.Year(2019), nil).WithOpeningBalance(f.Balance.Year(2018))
+
+	// Act
+	roe, roa, leverage := v.ReturnOnShareholdersEquity(), v.ReturnOnTotalAssets(), v.Leverage()
+
+	// Assert
+	assert.Equal(t, GOOD, roe)
+	assert.Equal(t, GOOD, roa)
+	assert.Equal(t, OK, leverage)
+}
+
+func TestValueRatingAnnualisesQuarters(t *testing.T) {
+	// Arrange
+	f, _ := LoadQuarterlyFundamentals(&YahooMockClient{}, "AAPL")
+	end, _ := f.Income.Latest()
+	quarter := func() *ValueRating {
+		return NewValueRating(f.Income.At(end.End), f.Balance.At(end.End), nil).WithOpeningBalance(f.Balance.Before(end.End))
+	}
 
 	// Act
+	raw, annualised := quarter(), quarter().WithPeriodsPerYear(4)
+	rawYears, _ := ratioFloat(raw.longTermDebtYearsToRepay())
+	years, _ := ratioFloat(annualised.longTermDebtYearsToRepay())
+	rawDuPont, _ := raw.DuPont()
+	d, _ := annualised.DuPont()
 
 	// Assert
+	assert.InDelta(t, rawYears/4, years, 1e-9)
+	assert.True(t, d.ReturnOnShareholdersEquity.Equal(rawDuPont.ReturnOnShareholdersEquity.Mul(decimal.New(4, 0))))
+	assert.True(t, d.ReturnOnTotalAssets.Equal(rawDuPont.ReturnOnTotalAssets.Mul(decimal.New(4, 0))))
+	assert.True(t, d.Leverage.Equal(rawDuPont.Leverage))
+	assert.True(t, d.NetMargin.Equal(rawDuPont.NetMargin))
 }